@@ -0,0 +1,19 @@
+package chi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	chiadapter "github.com/nktknshn/go-ergo-handler/adapters/chi"
+	"github.com/nktknshn/go-ergo-handler/adapters/internal/adaptertest"
+)
+
+func TestChiVarsGetter(t *testing.T) {
+	adaptertest.TestVarsGetter(t, chiadapter.New(), func(handler http.Handler) http.Handler {
+		router := chi.NewRouter()
+		router.Handle("/books/{id}", handler)
+		router.Handle("/books", handler)
+		return router
+	})
+}
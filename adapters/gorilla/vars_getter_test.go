@@ -0,0 +1,19 @@
+package gorilla_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/nktknshn/go-ergo-handler/adapters/gorilla"
+	"github.com/nktknshn/go-ergo-handler/adapters/internal/adaptertest"
+)
+
+func TestMuxVarsGetter(t *testing.T) {
+	adaptertest.TestVarsGetter(t, gorilla.New(), func(handler http.Handler) http.Handler {
+		router := mux.NewRouter()
+		router.Handle("/books/{id}", handler)
+		router.Handle("/books", handler)
+		return router
+	})
+}
@@ -0,0 +1,51 @@
+// Package adaptertest contains the checks shared by the tests of the VarsGetter adapters.
+package adaptertest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+// Case is a request to the router and the expected response.
+type Case struct {
+	URL          string
+	ExpectedCode int
+	ExpectedBody string
+}
+
+// RouterFunc returns the router serving the handler on /books/{id} and /books.
+type RouterFunc func(handler http.Handler) http.Handler
+
+var defaultCases = []Case{
+	{"/books/1", http.StatusOK, "1"},
+	{"/books/abc", http.StatusBadRequest, `{"error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`},
+	{"/books", http.StatusBadRequest, `{"error":"required router param is missing: id"}`},
+}
+
+// TestVarsGetter serves the handler parsing the int router param id with the getter and checks
+// the responses of the router to the default cases and the extra ones.
+func TestVarsGetter(t *testing.T, getter geh.VarsGetter, router RouterFunc, extra ...Case) {
+	t.Helper()
+	builder := geh.New()
+	attached := geh.RouterParam("id", geh.IgnoreContext(strconv.Atoi)).
+		WithVarsGetter(getter).
+		Attach(builder)
+	handler := router(builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strconv.Itoa(attached.Get(r))))
+	}))
+
+	for _, c := range slices.Concat(defaultCases, extra) {
+		t.Run(c.URL, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", c.URL, nil))
+			require.Equal(t, c.ExpectedCode, w.Code)
+			require.Equal(t, c.ExpectedBody, w.Body.String())
+		})
+	}
+}
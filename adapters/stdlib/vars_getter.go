@@ -0,0 +1,17 @@
+package stdlib
+
+import (
+	"net/http"
+)
+
+// ServeMuxVarsGetter gets the router params from the wildcards of the http.ServeMux patterns: /books/{id}.
+type ServeMuxVarsGetter struct{}
+
+func (s *ServeMuxVarsGetter) GetVar(r *http.Request, key string) (string, bool) {
+	v := r.PathValue(key)
+	return v, v != ""
+}
+
+func New() *ServeMuxVarsGetter {
+	return &ServeMuxVarsGetter{}
+}
@@ -0,0 +1,20 @@
+package stdlib_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nktknshn/go-ergo-handler/adapters/internal/adaptertest"
+	"github.com/nktknshn/go-ergo-handler/adapters/stdlib"
+)
+
+func TestServeMuxVarsGetter(t *testing.T) {
+	adaptertest.TestVarsGetter(t, stdlib.New(), func(handler http.Handler) http.Handler {
+		router := http.NewServeMux()
+		router.Handle("/books/{id}", handler)
+		router.Handle("/books", handler)
+		// the remaining wildcard matches the empty path value which is treated as missing
+		router.Handle("/files/{id...}", handler)
+		return router
+	}, adaptertest.Case{URL: "/files/", ExpectedCode: http.StatusBadRequest, ExpectedBody: `{"error":"required router param is missing: id"}`})
+}
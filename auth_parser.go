@@ -11,6 +11,28 @@ const (
 	defaultHttpStatusCodeErrUnauthorized = http.StatusUnauthorized
 )
 
+// DefaultAuthSecurityScheme is the security scheme name reported by the auth parsers by default.
+const DefaultAuthSecurityScheme = "bearerAuth"
+
+// AuthScheme describes how the token is passed, the auth parsers report it in their description.
+type AuthScheme struct {
+	// Type is "http" for the schemes of the Authorization header or "apiKey".
+	Type string
+	// Scheme is the scheme of the Authorization header: "bearer", "basic".
+	Scheme string
+	// In and Name are the location and the name of the header, query param or cookie of the api key.
+	In   ParamLocation
+	Name string
+}
+
+// AuthSchemeBearer is the scheme of TokenBearerFromHeader. It is the default scheme of the auth parsers.
+var AuthSchemeBearer = AuthScheme{Type: "http", Scheme: "bearer"}
+
+// AuthSchemeAPIKey is the scheme of the token passed in the header, query param or cookie with the name.
+func AuthSchemeAPIKey(in ParamLocation, name string) AuthScheme {
+	return AuthScheme{Type: "apiKey", In: in, Name: name}
+}
+
 var (
 	// Returned when the token is missing from the request.
 	ErrAuthMissingToken = errors.New("missing token")
@@ -25,6 +47,8 @@ type tokenValidator[T any] interface {
 type AuthParserType[T any, K any] struct {
 	key             K
 	tokenParserFunc TokenParserFunc
+	securityScheme  string
+	authScheme      AuthScheme
 }
 
 type TokenParserFunc = func(ctx context.Context, r *http.Request) (string, bool, error)
@@ -37,11 +61,24 @@ type TokenParserFunc = func(ctx context.Context, r *http.Request) (string, bool,
 // On success the data returned by the validator will be set to the context with the key.
 // Use WithHandlerErrorFunc to customize the error handling.
 func AuthParser[T any, K any](key K, tokenParser TokenParserFunc) *AuthParserType[T, K] {
-	return &AuthParserType[T, K]{key: key, tokenParserFunc: tokenParser}
+	return &AuthParserType[T, K]{key: key, tokenParserFunc: tokenParser, securityScheme: DefaultAuthSecurityScheme, authScheme: AuthSchemeBearer}
+}
+
+// WithSecurityScheme sets the security scheme name the parser is described with.
+func (a *AuthParserType[T, K]) WithSecurityScheme(name string) *AuthParserType[T, K] {
+	a.securityScheme = name
+	return a
+}
+
+// WithAuthScheme sets the scheme the parser is described with. It should match the token parser,
+// the default is AuthSchemeBearer.
+func (a *AuthParserType[T, K]) WithAuthScheme(scheme AuthScheme) *AuthParserType[T, K] {
+	a.authScheme = scheme
+	return a
 }
 
 func (a *AuthParserType[T, K]) Attach(tokenValidator tokenValidator[T], builder ParserAdder) *AttachedAuthParser[T, K] {
-	attached := &AttachedAuthParser[T, K]{tokenValidator, a.tokenParserFunc, a.key}
+	attached := &AttachedAuthParser[T, K]{tokenValidator, a.tokenParserFunc, a.key, a.securityScheme, a.authScheme}
 	builder.AddParser(attached)
 	return attached
 }
@@ -50,6 +87,8 @@ type AttachedAuthParser[T any, K any] struct {
 	tokenValidator  tokenValidator[T]
 	tokenParserFunc TokenParserFunc
 	key             K
+	securityScheme  string
+	authScheme      AuthScheme
 }
 
 // ParseRequest parses the request and returns the context and error.
@@ -71,6 +110,17 @@ func (a *AttachedAuthParser[T, K]) ParseRequest(ctx context.Context, w http.Resp
 	return context.WithValue(ctx, a.key, data), nil
 }
 
+func (a *AttachedAuthParser[T, K]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location:      ParamLocationAuth,
+		Name:          a.securityScheme,
+		Type:          typeOf[T](),
+		Required:      true,
+		AuthScheme:    &a.authScheme,
+		ErrorStatuses: uniqueStatuses(defaultHttpStatusCodeErrUnauthorized, defaultHttpStatusCodeErrInternal),
+	}}
+}
+
 func (a *AttachedAuthParser[T, K]) GetContext(ctx context.Context) *T {
 	return GetFromContext[*T](ctx, a.key)
 }
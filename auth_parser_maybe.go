@@ -8,16 +8,31 @@ import (
 type AuthParserMaybeType[T any, K any] struct {
 	key             K
 	tokenParserFunc TokenParserFunc
+	securityScheme  string
+	authScheme      AuthScheme
 }
 
 // AuthParserMaybe is the same as AuthParser but it allows the token to be missing or
 // validator to return false.
 func AuthParserMaybe[T any, K any](key K, tokenParser TokenParserFunc) *AuthParserMaybeType[T, K] {
-	return &AuthParserMaybeType[T, K]{key, tokenParser}
+	return &AuthParserMaybeType[T, K]{key, tokenParser, DefaultAuthSecurityScheme, AuthSchemeBearer}
+}
+
+// WithSecurityScheme sets the security scheme name the parser is described with.
+func (a *AuthParserMaybeType[T, K]) WithSecurityScheme(name string) *AuthParserMaybeType[T, K] {
+	a.securityScheme = name
+	return a
+}
+
+// WithAuthScheme sets the scheme the parser is described with. It should match the token parser,
+// the default is AuthSchemeBearer.
+func (a *AuthParserMaybeType[T, K]) WithAuthScheme(scheme AuthScheme) *AuthParserMaybeType[T, K] {
+	a.authScheme = scheme
+	return a
 }
 
 func (a *AuthParserMaybeType[T, K]) Attach(tokenValidator tokenValidator[T], builder ParserAdder) *AttachedAuthParserMaybe[T, K] {
-	attached := &AttachedAuthParserMaybe[T, K]{tokenValidator, a.tokenParserFunc, a.key}
+	attached := &AttachedAuthParserMaybe[T, K]{tokenValidator, a.tokenParserFunc, a.key, a.securityScheme, a.authScheme}
 	builder.AddParser(attached)
 	return attached
 }
@@ -26,6 +41,8 @@ type AttachedAuthParserMaybe[T any, K any] struct {
 	auth            tokenValidator[T]
 	tokenParserFunc TokenParserFunc
 	key             K
+	securityScheme  string
+	authScheme      AuthScheme
 }
 
 func (a *AttachedAuthParserMaybe[T, K]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
@@ -46,6 +63,17 @@ func (a *AttachedAuthParserMaybe[T, K]) ParseRequest(ctx context.Context, w http
 	return context.WithValue(ctx, a.key, data), nil
 }
 
+func (a *AttachedAuthParserMaybe[T, K]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location:      ParamLocationAuth,
+		Name:          a.securityScheme,
+		Type:          typeOf[T](),
+		Required:      false,
+		AuthScheme:    &a.authScheme,
+		ErrorStatuses: uniqueStatuses(defaultHttpStatusCodeErrInternal),
+	}}
+}
+
 func (a *AttachedAuthParserMaybe[T, K]) GetContextMaybe(ctx context.Context) (*T, bool) {
 	return GetFromContextMaybe[T](ctx, a.key)
 }
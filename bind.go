@@ -0,0 +1,235 @@
+package goergohandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultHttpStatusCodeErrBindValidation = http.StatusBadRequest
+)
+
+const bindTagName = "geh"
+
+// BindType is a parser that binds the request values into the fields of a struct T.
+type BindType[T any] struct {
+	fields []bindField
+}
+
+// bindField is a struct field bound to the value parsed by its parser.
+type bindField struct {
+	index    []int
+	pointer  bool
+	parser   ValueParser
+	param    ParamDescription
+	getValue func(ctx context.Context) (any, bool)
+	// the parser of a router param is made on attach when the VarsGetter is resolved
+	routerParam *RouterParamType[any]
+}
+
+// Bind is a parser that binds query params, router params, headers and payload into the struct T
+// according to the struct tags:
+//
+//	type getBooksRequest struct {
+//		BookID  int64         `geh:"path=book_id"`
+//		Limit   int           `geh:"query=limit"`
+//		Cursor  *cursor       `geh:"query=cursor"`
+//		Tenant  string        `geh:"header=X-Tenant,optional"`
+//		Payload updatePayload `geh:"body"`
+//	}
+//
+// For each field the matching QueryParam/RouterParam/Payload parser is made.
+// Pointer fields and fields with the optional option are not required.
+// Field types must implement WithParser, encoding.TextUnmarshaler or be one of the basic types.
+// Each field value and the resulting struct are validated if they implement WithValidation.
+// Bind panics if T is not a struct or a field has an unsupported type.
+func Bind[T any]() *BindType[T] {
+	t := typeOf[T]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Bind: %s is not a struct", t))
+	}
+	b := &BindType[T]{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup(bindTagName)
+		if !ok || tag == "-" {
+			continue
+		}
+		field, err := newBindField(f, tag)
+		if err != nil {
+			panic(fmt.Sprintf("Bind: %s.%s: %v", t, f.Name, err))
+		}
+		b.fields = append(b.fields, field)
+	}
+	return b
+}
+
+func newBindField(f reflect.StructField, tag string) (bindField, error) {
+	spec, opts, _ := strings.Cut(tag, ",")
+	location, name, _ := strings.Cut(spec, "=")
+	optional := opts == "optional"
+
+	field := bindField{index: f.Index}
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		field.pointer = true
+		optional = true
+		t = t.Elem()
+	}
+
+	if location == "body" {
+		p := &bindPayloadParser{t: t}
+		field.parser = p
+		field.getValue = func(ctx context.Context) (any, bool) {
+			v := ctx.Value(p)
+			return v, v != nil
+		}
+		field.param = p.Describe()[0]
+		return field, nil
+	}
+
+	if name == "" {
+		return field, fmt.Errorf("missing name in tag %q", tag)
+	}
+	parse, ok := stringParserFor(t)
+	if !ok {
+		return field, fmt.Errorf("unsupported type %s", t)
+	}
+
+	switch {
+	case location == "query" && optional:
+		p := &AttachedQueryParamMaybe[any]{QueryParamMaybe(name, parse)}
+		field.parser = p
+		field.param = p.Describe()[0]
+		field.getValue = func(ctx context.Context) (any, bool) {
+			v, ok := p.GetContextMaybe(ctx)
+			if !ok {
+				return nil, false
+			}
+			return *v, true
+		}
+	case location == "query":
+		p := &AttachedQueryParam[any]{QueryParam(name, parse)}
+		field.parser = p
+		field.param = p.Describe()[0]
+		field.getValue = func(ctx context.Context) (any, bool) {
+			return p.GetContext(ctx), true
+		}
+	case location == "path":
+		field.routerParam = RouterParam(name, parse)
+		field.param = (&AttachedRouterParam[any]{rp: field.routerParam}).Describe()[0]
+	case location == "header" && optional:
+		p := &AttachedHeaderMaybe[any]{HeaderMaybe(name, parse)}
+		field.parser = p
+		field.param = p.Describe()[0]
+		field.getValue = func(ctx context.Context) (any, bool) {
+			v, ok := p.GetContextMaybe(ctx)
+			if !ok {
+				return nil, false
+			}
+			return *v, true
+		}
+	case location == "header":
+		p := &AttachedHeader[any]{Header(name, parse)}
+		field.parser = p
+		field.param = p.Describe()[0]
+		field.getValue = func(ctx context.Context) (any, bool) {
+			return p.GetContext(ctx), true
+		}
+	default:
+		return field, fmt.Errorf("unknown location in tag %q", tag)
+	}
+	field.param.Type = f.Type
+	return field, nil
+}
+
+func (b *BindType[T]) Attach(builder ParserAdder) *AttachedBind[T] {
+	fields := slices.Clone(b.fields)
+	for i := range fields {
+		if rp := fields[i].routerParam; rp != nil {
+			p := &AttachedRouterParam[any]{rp, resolveVarsGetter(rp.VarsGetter, builder)}
+			fields[i].parser = p
+			fields[i].getValue = func(ctx context.Context) (any, bool) {
+				return p.GetContext(ctx), true
+			}
+		}
+	}
+	a := &AttachedBind[T]{fields}
+	builder.AddParser(a)
+	return a
+}
+
+type AttachedBind[T any] struct {
+	fields []bindField
+}
+
+func (a *AttachedBind[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	var err error
+	for _, f := range a.fields {
+		ctx, err = f.parser.ParseRequest(ctx, w, r)
+		if err != nil {
+			return ctx, err
+		}
+	}
+
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	for _, f := range a.fields {
+		fv, ok := f.getValue(ctx)
+		if !ok {
+			continue
+		}
+		target := rv.FieldByIndex(f.index)
+		value := reflect.ValueOf(fv)
+		if f.pointer {
+			ptr := reflect.New(target.Type().Elem())
+			ptr.Elem().Set(value)
+			value = ptr
+		}
+		target.Set(value)
+	}
+
+	err = ValidateWithValidation(v)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrBindValidation)
+	}
+	return context.WithValue(ctx, a, v), nil
+}
+
+func (a *AttachedBind[T]) Describe() []ParamDescription {
+	params := make([]ParamDescription, 0, len(a.fields))
+	for _, f := range a.fields {
+		params = append(params, f.param)
+	}
+	return params
+}
+
+func (a *AttachedBind[T]) Get(r *http.Request) T {
+	return a.GetContext(r.Context())
+}
+
+func (a *AttachedBind[T]) GetContext(ctx context.Context) T {
+	return GetFromContext[T](ctx, a)
+}
+
+// bindPayloadParser decodes the payload into a value of type t.
+type bindPayloadParser struct {
+	t reflect.Type
+}
+
+func (p *bindPayloadParser) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	pl := reflect.New(p.t)
+	err := decodePayload(w, r, pl.Interface(), payloadOptions{})
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, p, pl.Elem().Interface()), nil
+}
+
+func (p *bindPayloadParser) Describe() []ParamDescription {
+	return []ParamDescription{describePayload(p.t, payloadOptions{})}
+}
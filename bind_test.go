@@ -0,0 +1,151 @@
+package goergohandler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/nktknshn/go-ergo-handler/adapters/gorilla"
+	"github.com/stretchr/testify/require"
+)
+
+type bindRequest struct {
+	BookID  paramBookIDType            `geh:"path=book_id"`
+	Limit   int                        `geh:"query=limit"`
+	Cursor  *paramBookIDWithParserType `geh:"query=cursor"`
+	Timeout time.Duration              `geh:"query=timeout,optional"`
+	Tenant  string                     `geh:"header=X-Tenant"`
+	Payload testPayload                `geh:"body"`
+	Ignored string
+}
+
+func (r bindRequest) Validate() error {
+	if r.Limit > 100 {
+		return errors.New("limit is too big")
+	}
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	builder := geh.New().WithVarsGetter(gorilla.New())
+	req := geh.Bind[bindRequest]().Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		v := req.Get(r)
+		cursor := "<nil>"
+		if v.Cursor != nil {
+			cursor = string(*v.Cursor)
+		}
+		fmt.Fprintf(w, "%d %d %s %s %s %s", v.BookID, v.Limit, cursor, v.Timeout, v.Tenant, v.Payload.SomeKey)
+	})
+
+	router := mux.NewRouter()
+	router.Handle("/books/{book_id}", handler)
+
+	cases := []struct {
+		name         string
+		url          string
+		tenant       string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "all values",
+			url:          "/books/1?limit=10&cursor=abc&timeout=1s",
+			tenant:       "t1",
+			body:         `{"some_key":"value"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: "1 10 abc_parsed 1s t1 value",
+		},
+		{
+			name:         "optional values are missing",
+			url:          "/books/1?limit=10",
+			tenant:       "t1",
+			body:         `{"some_key":"value"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: "1 10 <nil> 0s t1 value",
+		},
+		{
+			name:         "missing query param",
+			url:          "/books/1",
+			tenant:       "t1",
+			body:         `{"some_key":"value"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"required query param is missing: limit"}`,
+		},
+		{
+			name:         "invalid query param",
+			url:          "/books/1?limit=abc",
+			tenant:       "t1",
+			body:         `{"some_key":"value"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"invalid int value: abc"}`,
+		},
+		{
+			name:         "field validation",
+			url:          "/books/0?limit=10",
+			tenant:       "t1",
+			body:         `{"some_key":"value"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"invalid book id"}`,
+		},
+		{
+			name:         "missing header",
+			url:          "/books/1?limit=10",
+			body:         `{"some_key":"value"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"required header is missing: X-Tenant"}`,
+		},
+		{
+			name:         "payload validation",
+			url:          "/books/1?limit=10",
+			tenant:       "t1",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"some_key is required"}`,
+		},
+		{
+			name:         "struct validation",
+			url:          "/books/1?limit=1000",
+			tenant:       "t1",
+			body:         `{"some_key":"value"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"limit is too big"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", c.url, strings.NewReader(c.body))
+			if c.tenant != "" {
+				r.Header.Set("x-tenant", c.tenant)
+			}
+			router.ServeHTTP(w, r)
+			require.Equal(t, c.expectedCode, w.Code)
+			require.Equal(t, c.expectedBody, w.Body.String())
+		})
+	}
+
+	params := builder.Describe()
+	require.Len(t, params, 6)
+	require.Equal(t, "path book_id goergohandler_test.paramBookIDType required", params[0].String())
+	require.Equal(t, "query cursor *goergohandler_test.paramBookIDWithParserType optional", params[2].String())
+	require.Equal(t, "body goergohandler_test.testPayload required", params[5].String())
+}
+
+func TestBind_PanicsOnUnsupportedField(t *testing.T) {
+	type request struct {
+		Values map[string]string `geh:"query=values"`
+	}
+	require.Panics(t, func() {
+		geh.Bind[request]()
+	})
+}
@@ -1,10 +1,14 @@
 package goergohandler
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
 )
 
 type MiddlewareFunc = func(http.Handler) http.Handler
@@ -16,30 +20,174 @@ type HandleErrorFunc func(ctx context.Context, w http.ResponseWriter, r *http.Re
 type HandleResultFunc func(ctx context.Context, w http.ResponseWriter, r *http.Request, result any)
 
 // Builder is a builder for the handler.
+// Parsers are turned into the middlewares chain only when the handler is built, so the order
+// of attaching parsers and setting the handlers does not matter.
 type Builder struct {
-	parsers           []ValueParser
-	middlewares       []MiddlewareFunc
+	parsers           []parserEntry
 	handlerErrorFunc  HandleErrorFunc
 	handlerResultFunc HandleResultFunc
+	collectErrors     bool
+	varsGetter        VarsGetter
+	encoders          []mediaTypeEncoder
+}
+
+// parserEntry is a parser attached to the builder.
+type parserEntry struct {
+	parser ValueParser
+	// overrides the builder's handlerErrorFunc if set
+	handlerErrorFunc HandleErrorFunc
 }
 
 func New() *Builder {
 	return &Builder{}
 }
 
+// Clone returns a copy of the builder with the same parsers and handlers.
+// Parsers attached to the copy are not added to the original builder and vice versa.
+// Getters of the parsers attached to the original builder can be used with the handlers built by the copy.
+// Example:
+//
+//	base := geh.New().WithHandlerErrorFunc(errorFunc)
+//	user := authParser.Attach(validator, base)
+//
+//	builder := base.Clone()
+//	bookID := paramBookID.Attach(builder)
+func (b *Builder) Clone() *Builder {
+	c := *b
+	c.parsers = c.cloneParsers(b.parsers)
+	c.encoders = slices.Clone(b.encoders)
+	return &c
+}
+
+// cloneParsers copies the entries for the builder. The concurrent groups are copied too,
+// so parsers attached to a group of one builder are not added to the other.
+func (b *Builder) cloneParsers(entries []parserEntry) []parserEntry {
+	cloned := slices.Clone(entries)
+	for i, e := range cloned {
+		if g, ok := e.parser.(*concurrentParsers); ok {
+			cloned[i].parser = &concurrentParsers{builder: b, parsers: slices.Clone(g.parsers)}
+		}
+	}
+	return cloned
+}
+
+// Extend appends the parsers of the given builders to the builder keeping their order.
+// Handlers of the given builders are ignored. The given builders are not modified.
+// The settings are merged: the response encoders for the media types missing in the builder are added,
+// the VarsGetter is taken if the builder has none and collecting errors is enabled if any of the builders collects them.
+// The VarsGetter of the builder wins over the VarsGetters of the given builders, the first one set is taken otherwise.
+func (b *Builder) Extend(others ...*Builder) *Builder {
+	for _, o := range others {
+		b.parsers = append(b.parsers, b.cloneParsers(o.parsers)...)
+		for _, e := range o.encoders {
+			if !slices.ContainsFunc(b.encoders, func(be mediaTypeEncoder) bool { return be.mediaType == e.mediaType }) {
+				b.encoders = append(b.encoders, e)
+			}
+		}
+		if b.varsGetter == nil {
+			b.varsGetter = o.varsGetter
+		}
+		b.collectErrors = b.collectErrors || o.collectErrors
+	}
+	return b
+}
+
 // AddParser adds a parser to the builder.
-// The handlerErrorFunc linked to the builder will be used to handle the error returned by the parser.
+// The handlerErrorFunc linked to the builder at the moment the handler is built will be used to handle the error returned by the parser.
 func (b *Builder) AddParser(parser ValueParser) {
-	b.parsers = append(b.parsers, parser)
-	b.middlewares = append(b.middlewares, ValueParserToMiddleware(parser, b.handlerErrorFunc))
+	b.parsers = append(b.parsers, parserEntry{parser: parser})
+}
+
+// AddParserWithErrorFunc adds a parser to the builder. Errors returned by the parser will be handled by f
+// instead of the builder's handlerErrorFunc.
+func (b *Builder) AddParserWithErrorFunc(parser ValueParser, f HandleErrorFunc) {
+	b.parsers = append(b.parsers, parserEntry{parser: parser, handlerErrorFunc: f})
+}
+
+// WithParserErrorFunc returns a ParserAdder that attaches parsers to the builder with their own error handler.
+// Example:
+//
+// bookID := paramBookID.Attach(builder.WithParserErrorFunc(notFoundErrorFunc))
+func (b *Builder) WithParserErrorFunc(f HandleErrorFunc) ParserAdder {
+	return &errorFuncParserAdder{b, f}
+}
+
+// Concurrent returns a ParserAdder whose parsers run concurrently with each other
+// at the current position of the chain. Use it for independent parsers, like an auth parser making
+// a network call and a payload parser. The values the parsers put into the context are merged.
+// The first failure cancels the context of the other parsers in the group.
+// Parsers attached to the builder after the group see the values of the group parsers.
+// Parsers of the group should not depend on each other or write the response.
+// The multipart parsers of the group run one by one before the others.
+// Example:
+//
+//	group := builder.Concurrent()
+//	user := authParser.Attach(validator, group)
+//	payload := payloadBook.Attach(group)
+func (b *Builder) Concurrent() ParserAdder {
+	g := &concurrentParsers{builder: b}
+	b.AddParser(g)
+	return g
+}
+
+type errorFuncParserAdder struct {
+	builder          *Builder
+	handlerErrorFunc HandleErrorFunc
+}
+
+func (a *errorFuncParserAdder) AddParser(parser ValueParser) {
+	a.builder.AddParserWithErrorFunc(parser, a.handlerErrorFunc)
+}
+
+func (a *errorFuncParserAdder) VarsGetter() VarsGetter {
+	return a.builder.VarsGetter()
 }
 
 // WithHandlerErrorFunc sets a function that will be called when an error is returned by some of the parsers
+// or by the handler. It applies to all the parsers regardless of when they were attached.
 func (b *Builder) WithHandlerErrorFunc(f HandleErrorFunc) *Builder {
 	b.handlerErrorFunc = f
 	return b
 }
 
+// WithVarsGetter sets the VarsGetter used by the router params attached to the builder after this call.
+// Router params with their own VarsGetter are not affected.
+func (b *Builder) WithVarsGetter(varsGetter VarsGetter) *Builder {
+	b.varsGetter = varsGetter
+	return b
+}
+
+// VarsGetter returns the VarsGetter set by WithVarsGetter.
+func (b *Builder) VarsGetter() VarsGetter {
+	return b.varsGetter
+}
+
+// WithResponseEncoder registers the encoder for the media type enabling the content negotiation.
+// The encoder is picked by the Accept header of the request and is used by DefaultHandlerResultFunc and
+// DefaultHandlerErrorFunc. JSON stays the default for the requests accepting any type.
+// If nothing matches the Accept header, the handler responds with 406 Not Acceptable.
+// Without registered encoders the negotiation is disabled and JSON is always used.
+// Example:
+//
+//	builder.WithResponseEncoder("application/xml", geh.XMLEncoder{})
+func (b *Builder) WithResponseEncoder(mediaType string, encoder ResponseEncoder) *Builder {
+	if len(b.encoders) == 0 {
+		b.encoders = append(b.encoders, defaultResponseEncoder)
+	}
+	b.encoders = append(b.encoders, mediaTypeEncoder{strings.ToLower(mediaType), encoder})
+	return b
+}
+
+// WithCollectErrors makes the handler run all the parsers and respond with all the failures at once
+// instead of stopping at the first failing parser. The failures are collected into ParseErrors
+// with one entry per parameter. Only the client errors (400 and 422) are collected, other errors
+// and the errors of the parsers with their own error handler stop the chain immediately.
+// The handler is not called if any of the parsers failed.
+func (b *Builder) WithCollectErrors() *Builder {
+	b.collectErrors = true
+	return b
+}
+
 // WithHandlerResultFunc sets a function that will be called when a result is returned by some of the parsers
 func (b *Builder) WithHandlerResultFunc(f HandleResultFunc) *Builder {
 	b.handlerResultFunc = f
@@ -47,8 +195,9 @@ func (b *Builder) WithHandlerResultFunc(f HandleResultFunc) *Builder {
 }
 
 // BuildHandler builds a handler that will call the given function after all the parsers succeed.
+// The returned handler implements DescribedParser describing the attached parsers.
 func (b *Builder) BuildHandler(f func(h http.ResponseWriter, r *http.Request)) http.Handler {
-	return b.ApplyMiddleware(http.HandlerFunc(f))
+	return &describedHandler{b.ApplyMiddleware(http.HandlerFunc(f)), b.Describe()}
 }
 
 // BuildHandlerWrapped builds a handler that is wrapped with result and error handlers.
@@ -59,13 +208,17 @@ func (b *Builder) BuildHandler(f func(h http.ResponseWriter, r *http.Request)) h
 // Default failure HTTP status codes are 400 for request parsing and 500 for an error returned by the handler.
 // Success HTTP status code is 200.
 // This can be changed by setting the HandlerErrorFunc and HandlerResultFunc or by returning a ErrorWithHttpStatus/ResponseWithHttpStatus from the handler or parsers.
+// The returned handler implements DescribedParser describing the attached parsers.
 func (b *Builder) BuildHandlerWrapped(f func(h http.ResponseWriter, r *http.Request) (any, error)) http.Handler {
+	handlerErrorFunc := b.handlerErrorFunc
+	handlerResultFunc := b.handlerResultFunc
+
 	wrapped := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		result, err := f(w, r)
 
-		if err != nil && b.handlerErrorFunc != nil {
-			// b.handlerErrorFunc(r.Context(), w, r, InternalServerError(err))
-			b.handlerErrorFunc(r.Context(), w, r, err)
+		if err != nil && handlerErrorFunc != nil {
+			// handlerErrorFunc(r.Context(), w, r, InternalServerError(err))
+			handlerErrorFunc(r.Context(), w, r, err)
 			return
 		}
 
@@ -75,25 +228,82 @@ func (b *Builder) BuildHandlerWrapped(f func(h http.ResponseWriter, r *http.Requ
 			return
 		}
 
-		if b.handlerResultFunc != nil {
-			b.handlerResultFunc(r.Context(), w, r, result)
+		if handlerResultFunc != nil {
+			handlerResultFunc(r.Context(), w, r, result)
 			return
 		}
 
 		DefaultHandlerResultFunc(r.Context(), w, r, result)
 	})
 
-	return b.ApplyMiddleware(wrapped)
+	return &describedHandler{b.ApplyMiddleware(wrapped), b.Describe()}
+}
+
+// Describe returns the descriptions of the attached parsers in the order they were attached.
+// Parsers that do not implement DescribedParser are skipped.
+func (b *Builder) Describe() []ParamDescription {
+	var params []ParamDescription
+	for _, e := range b.parsers {
+		params = append(params, DescribeParser(e.parser)...)
+	}
+	return params
 }
 
-// ApplyMiddleware applies the middlewares to the handler
+// ApplyMiddleware applies the middlewares made from the attached parsers to the handler
 func (b *Builder) ApplyMiddleware(hh http.Handler) http.Handler {
-	for i := len(b.middlewares) - 1; i >= 0; i-- {
-		hh = b.middlewares[i](hh)
+	b.checkDuplicateParams()
+	middlewares := b.middlewares()
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		hh = middlewares[i](hh)
 	}
 	return hh
 }
 
+// checkDuplicateParams panics if a query, path, header or cookie param with the same name is attached twice.
+// Header names are compared case insensitively.
+func (b *Builder) checkDuplicateParams() {
+	type param struct {
+		location ParamLocation
+		name     string
+	}
+	seen := map[param]bool{}
+	for _, d := range b.Describe() {
+		switch d.Location {
+		case ParamLocationQuery, ParamLocationPath, ParamLocationHeader, ParamLocationCookie:
+		default:
+			continue
+		}
+		key := param{d.Location, d.Name}
+		if d.Location == ParamLocationHeader {
+			// headers are matched case insensitively
+			key.name = http.CanonicalHeaderKey(d.Name)
+		}
+		if seen[key] {
+			panic(fmt.Sprintf("Builder: %s param %q is attached more than once", d.Location, d.Name))
+		}
+		seen[key] = true
+	}
+}
+
+// middlewares converts the attached parsers to middlewares using the current error handlers.
+func (b *Builder) middlewares() []MiddlewareFunc {
+	middlewares := []MiddlewareFunc{cleanupMiddleware}
+	if len(b.encoders) > 0 {
+		middlewares = append(middlewares, negotiatingMiddleware(slices.Clone(b.encoders), b.handlerErrorFunc))
+	}
+	if b.collectErrors {
+		return append(middlewares, collectingMiddleware(slices.Clone(b.parsers), b.handlerErrorFunc))
+	}
+	for _, e := range b.parsers {
+		handlerErrorFunc := e.handlerErrorFunc
+		if handlerErrorFunc == nil {
+			handlerErrorFunc = b.handlerErrorFunc
+		}
+		middlewares = append(middlewares, ValueParserToMiddleware(e.parser, handlerErrorFunc))
+	}
+	return middlewares
+}
+
 // ValueParserToMiddleware converts a ValueParser to a MiddlewareFunc. If ParseRequest returns an error, the error will be handled by the handlerErrorFunc or DefaultHandlerErrorFunc if the handlerErrorFunc is nil.
 func ValueParserToMiddleware(parser ValueParser, handlerErrorFunc HandleErrorFunc) MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
@@ -113,8 +323,10 @@ func ValueParserToMiddleware(parser ValueParser, handlerErrorFunc HandleErrorFun
 }
 
 // errorResponse is the default error response to be marshalled to json {"error": "error message"}.
+// Errors implementing ErrorWithDetails add {"details": ...}.
 type errorResponse struct {
-	Error string `json:"error"`
+	Error   string `json:"error"`
+	Details any    `json:"details,omitempty"`
 }
 
 // successResponse is the default success response to be marshalled to json {"result": result}.
@@ -147,68 +359,129 @@ type ResultHandler interface {
 // }
 
 // By default, the error will be marshalled to json {"error": "error message"}.
+// If the builder has response encoders registered, the encoder negotiated for the request is used.
 // Default http status code is 500. Return ErrorWithHttpStatus to customize the http status code.
 // Implement ErrorWithResponseWriter or ErrorWithHeaderWriter for your errors to customize the response body or just headers.
-// The method can be overridden by setting WithHandlerErrorFunc to builder before attaching any parsers.
-var DefaultHandlerErrorFunc HandleErrorFunc = func(_ context.Context, w http.ResponseWriter, _ *http.Request, err error) {
+// Implement ErrorWithDetails to add {"details": ...} to the response. If the details cannot be encoded,
+// the response is 500 with the internal server error message.
+// The method can be overridden by setting WithHandlerErrorFunc to builder or WithParserErrorFunc for a single parser.
+var DefaultHandlerErrorFunc HandleErrorFunc = func(ctx context.Context, w http.ResponseWriter, _ *http.Request, err error) {
+	encoder, contentType := ResponseEncoderFromContext(ctx)
+	message := err.Error()
+	writeHeader := writeInternalErrorHeader
 
 	switch err := err.(type) {
+	case InternalServerError:
+		// the original error is not exposed to the client
+		message = err.msg
 	case ErrorWithResponseWriter:
 		err.WriteResponse(w)
 		return
 	case ErrorWithHeaderWriter:
-		w.Header().Set("Content-Type", "application/json")
-		err.WriteHeader(w)
-	default:
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(defaultHttpStatusCodeErrInternal)
+		writeHeader = err.WriteHeader
 	}
 
-	bs, err := json.Marshal(errorResponse{Error: err.Error()})
-	if err != nil {
-		slog.Error("error marshalling json", "error", err)
-		return
+	var details any
+	var withDetails ErrorWithDetails
+	if errors.As(err, &withDetails) {
+		details = withDetails.ErrorDetails()
 	}
-	_, err = w.Write(bs)
-	if err != nil {
+
+	// the body is encoded before the status is written, so a failure still can be reported with 500
+	var body bytes.Buffer
+	if err := encoder.EncodeError(&body, message, details); err != nil {
+		slog.Error("error encoding response", "error", err)
+		body.Reset()
+		writeHeader = writeInternalErrorHeader
+		if err := encoder.EncodeError(&body, internalServerErrorMessage, nil); err != nil {
+			slog.Error("error encoding response", "error", err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	writeHeader(w)
+	if _, err := w.Write(body.Bytes()); err != nil {
 		slog.Error("error sending response", "error", err)
-		return
+	}
+}
+
+func writeInternalErrorHeader(w http.ResponseWriter) {
+	w.WriteHeader(defaultHttpStatusCodeErrInternal)
+}
+
+// unwrapResult unwraps ResponseWithCookies and ResponseWithHttpStatus nested in any order.
+// It returns the wrapped response, the outermost status with a code and the cookies of all the wrappers.
+func unwrapResult(result any) (any, *ResponseWithHttpStatus, []*http.Cookie) {
+	var (
+		status  *ResponseWithHttpStatus
+		cookies []*http.Cookie
+	)
+	for {
+		switch r := result.(type) {
+		case ResponseWithCookies:
+			cookies = append(cookies, r.Cookies...)
+			result = r.Response
+		case ResponseWithHttpStatus:
+			if status == nil || status.HttpStatusCode == 0 {
+				status = &r
+			}
+			result = r.Response
+		default:
+			return result, status, cookies
+		}
 	}
 }
 
 // By default, the result will be marshalled to json {"result": result}.
+// If the builder has response encoders registered, the encoder negotiated for the request is used.
 // Status code is 200. Return ResponseWithHttpStatus to customize the http status code.
+// Return ResponseWithCookies to set cookies, it can wrap and be wrapped by ResponseWithHttpStatus.
 // Implement ResponseWithResponseWriter for your results to customize the response body and headers.
+// Implement ResponseWithLinks to set the Link header, e.g. Page does it.
 // Nil result will be marshalled to json {"result": {}}.
+// If the result cannot be encoded, the error is handled by DefaultHandlerErrorFunc as an internal server error.
 // The method can be overridden by setting WithHandlerResultFunc.
-var DefaultHandlerResultFunc HandleResultFunc = func(_ context.Context, w http.ResponseWriter, _ *http.Request, result any) {
-	resultData := result
-
-	switch result := result.(type) {
-	case ResponseWithResponseWriter:
-		result.WriteResponse(w)
-		return
-	case ResponseWithHttpStatus:
-		w.Header().Set("Content-Type", "application/json")
-		result.WriteHeaders(w)
-		resultData = result.Response
-	default:
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+var DefaultHandlerResultFunc HandleResultFunc = func(ctx context.Context, w http.ResponseWriter, r *http.Request, result any) {
+	encoder, contentType := ResponseEncoderFromContext(ctx)
+
+	result, withStatus, cookies := unwrapResult(result)
+	withWriter, isWriter := result.(ResponseWithResponseWriter)
+	writesResponse := withStatus == nil && isWriter
+
+	// the body is encoded before the headers are written, so a failure still can be reported with 500
+	var body bytes.Buffer
+	if !writesResponse {
+		if result == nil {
+			result = struct{}{}
+		}
+		if err := encoder.EncodeResult(&body, result); err != nil {
+			slog.Error("error encoding response", "error", err)
+			DefaultHandlerErrorFunc(ctx, w, r, NewInternalServerError(err))
+			return
+		}
 	}
 
-	if resultData == nil {
-		resultData = struct{}{}
+	for _, cookie := range cookies {
+		http.SetCookie(w, cookie)
+	}
+	if withLinks, ok := result.(ResponseWithLinks); ok {
+		withLinks.WriteLinks(w, r)
 	}
 
-	bs, err := json.Marshal(successResponse{Result: resultData})
-	if err != nil {
-		slog.Error("error marshalling json", "error", err)
+	if writesResponse {
+		withWriter.WriteResponse(w)
 		return
 	}
-	_, err = w.Write(bs)
-	if err != nil {
+
+	w.Header().Set("Content-Type", contentType)
+	if withStatus != nil {
+		withStatus.WriteHeaders(w)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if _, err := w.Write(body.Bytes()); err != nil {
 		slog.Error("error sending response", "error", err)
-		return
 	}
 }
@@ -7,9 +7,12 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/nktknshn/go-ergo-handler/adapters/gorilla"
+	"github.com/nktknshn/go-ergo-handler/adapters/stdlib"
 	"github.com/stretchr/testify/require"
 )
 
@@ -119,3 +122,217 @@ func TestBuilder_BuildHandlerWrapped(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_HandlerErrorFuncSetAfterAttach(t *testing.T) {
+	b := geh.New()
+	geh.QueryParamInt("limit").Attach(b)
+	b.WithHandlerErrorFunc(func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(`CUSTOM`))
+	})
+	handler := b.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusTeapot, w.Code)
+	require.Equal(t, `CUSTOM`, w.Body.String())
+}
+
+func TestBuilder_WithParserErrorFunc(t *testing.T) {
+	b := geh.New()
+	b.WithHandlerErrorFunc(func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(`BUILDER`))
+	})
+	geh.QueryParamInt("limit").Attach(b)
+	geh.QueryParamInt("offset").Attach(b.WithParserErrorFunc(func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`PARSER`))
+	}))
+	handler := b.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`OK`))
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?offset=1", nil))
+	require.Equal(t, http.StatusTeapot, w.Code)
+	require.Equal(t, `BUILDER`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?limit=1", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, `PARSER`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?limit=1&offset=1", nil))
+	require.Equal(t, `OK`, w.Body.String())
+}
+
+func TestBuilder_Clone(t *testing.T) {
+	base := geh.New().WithHandlerErrorFunc(func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	tenant := geh.QueryParamString("tenant").Attach(base)
+
+	child1 := base.Clone()
+	limit := geh.QueryParamInt("limit").Attach(child1)
+
+	child2 := base.Clone()
+	offset := geh.QueryParamInt("offset").Attach(child2)
+
+	require.Len(t, base.Describe(), 1)
+	require.Len(t, child1.Describe(), 2)
+	require.Len(t, child2.Describe(), 2)
+
+	handler := child1.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf("%s:%d", tenant.Get(r), limit.Get(r))))
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?tenant=t1&limit=10", nil))
+	require.Equal(t, "t1:10", w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?limit=10", nil))
+	require.Equal(t, http.StatusTeapot, w.Code)
+
+	handler = child2.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf("%s:%d", tenant.Get(r), offset.Get(r))))
+	})
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?tenant=t2&offset=5", nil))
+	require.Equal(t, "t2:5", w.Body.String())
+}
+
+func TestBuilder_Extend(t *testing.T) {
+	shared := geh.New()
+	tenant := geh.QueryParamString("tenant").Attach(shared)
+
+	b := geh.New()
+	limit := geh.QueryParamInt("limit").Attach(b)
+	b.Extend(shared)
+
+	require.Len(t, shared.Describe(), 1)
+	require.Equal(t, []string{"limit", "tenant"}, []string{b.Describe()[0].Name, b.Describe()[1].Name})
+
+	handler := b.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf("%s:%d", tenant.Get(r), limit.Get(r))))
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?tenant=t1&limit=10", nil))
+	require.Equal(t, "t1:10", w.Body.String())
+}
+
+func TestBuilder_UniqueContextKeys(t *testing.T) {
+	// the same query param name used by builders composed together
+	first := geh.New()
+	idInt := geh.QueryParamInt("id").Attach(first)
+	second := geh.New()
+	idString := geh.QueryParamString("id").Attach(second)
+
+	handlerInt := first.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf("%d", idInt.Get(r)+1)))
+	})
+	handler := second.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(idString.Get(r) + ":"))
+		handlerInt.ServeHTTP(w, r)
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?id=1", nil))
+	require.Equal(t, "1:2", w.Body.String())
+}
+
+type envelopePayload struct {
+	Kind string         `json:"kind"`
+	Data map[string]any `json:"data"`
+}
+
+type bookEnvelopePayload struct {
+	Data struct {
+		Title string `json:"title"`
+	} `json:"data"`
+}
+
+func TestBuilder_MultiplePayloads(t *testing.T) {
+	b := geh.New()
+	envelope := geh.Payload[envelopePayload]().Attach(b)
+	book := geh.Payload[bookEnvelopePayload]().Attach(b)
+
+	handler := b.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(envelope.Get(r).Kind + ":" + book.Get(r).Data.Title))
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"kind":"book","data":{"title":"Dune"}}`)))
+	require.Equal(t, "book:Dune", w.Body.String())
+}
+
+func TestBuilder_DuplicateParams(t *testing.T) {
+	b := geh.New()
+	geh.QueryParamInt("limit").Attach(b)
+	geh.QueryParamIntMaybe("limit").Attach(b)
+
+	require.PanicsWithValue(t, `Builder: query param "limit" is attached more than once`, func() {
+		b.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	b = geh.New()
+	geh.HeaderString("X-Tenant").Attach(b)
+	geh.HeaderStringMaybe("x-tenant").Attach(b)
+	require.PanicsWithValue(t, `Builder: header param "x-tenant" is attached more than once`, func() {
+		b.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	b = geh.New()
+	geh.QueryParamInt("id").Attach(b)
+	geh.RouterParamInt64("id").Attach(b)
+	require.NotPanics(t, func() {
+		b.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	})
+}
+
+func TestBuilder_CloneConcurrentGroup(t *testing.T) {
+	base := geh.New()
+	group := base.Concurrent()
+	geh.QueryParamInt("limit").Attach(group)
+
+	child := base.Clone()
+	geh.QueryParamInt("offset").Attach(group)
+
+	require.Len(t, base.Describe(), 2)
+	require.Len(t, child.Describe(), 1)
+
+	extended := geh.New().Extend(base)
+	geh.QueryParamInt("page").Attach(group)
+	require.Len(t, base.Describe(), 3)
+	require.Len(t, extended.Describe(), 2)
+}
+
+func TestBuilder_ExtendSettings(t *testing.T) {
+	shared := geh.New().
+		WithVarsGetter(stdlib.New()).
+		WithCollectErrors().
+		WithResponseEncoder("application/xml", geh.XMLEncoder{})
+	geh.QueryParamInt("limit").Attach(shared)
+	geh.QueryParamInt("offset").Attach(shared)
+
+	b := geh.New().Extend(shared)
+	require.IsType(t, &stdlib.ServeMuxVarsGetter{}, b.VarsGetter())
+
+	handler := b.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?limit=a&offset=b", nil)
+	r.Header.Set("Accept", "application/xml")
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), "limit")
+	require.Contains(t, w.Body.String(), "offset")
+
+	// the VarsGetter of the extended builder wins
+	b = geh.New().WithVarsGetter(gorilla.New()).Extend(shared)
+	require.IsType(t, &gorilla.MuxVarsGetter{}, b.VarsGetter())
+}
@@ -0,0 +1,46 @@
+package goergohandler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// requestCleanup holds the functions to be called when the request is done.
+type requestCleanup struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+type requestCleanupKeyType struct{}
+
+var requestCleanupKey requestCleanupKeyType
+
+// RegisterCleanup registers a function to be called after the handler built by the builder returns.
+// Functions are called in the reverse order. It returns false if the context does not belong to
+// a request served by a handler built by the builder.
+func RegisterCleanup(ctx context.Context, f func()) bool {
+	c, ok := ctx.Value(requestCleanupKey).(*requestCleanup)
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.funcs = append(c.funcs, f)
+	return true
+}
+
+// cleanupMiddleware calls the registered cleanup functions after the next handler returns.
+func cleanupMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := &requestCleanup{}
+		defer func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			for i := len(c.funcs) - 1; i >= 0; i-- {
+				c.funcs[i]()
+			}
+		}()
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestCleanupKey, c)))
+	})
+}
@@ -0,0 +1,251 @@
+// Command geh-enum generates the Parse and Validate methods for the string enum types,
+// so they can be used with QueryParamWithParser, RouterParamWithParser, HeaderWithParser and payloads.
+//
+// The allowed values are the constants of the type declared in the package:
+//
+//	//go:generate go run github.com/nktknshn/go-ergo-handler/cmd/geh-enum -type=Status
+//	type Status string
+//
+//	const (
+//		StatusDraft     Status = "draft"
+//		StatusPublished Status = "published"
+//	)
+//
+// The methods are written to status_enum.go next to the package files.
+// With -case-insensitive Parse accepts the values in any case.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the enum type, required")
+	caseInsensitive := flag.Bool("case-insensitive", false, "accept the values in any case")
+	output := flag.String("output", "", "output file, default is <type>_enum.go in the package directory")
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("geh-enum: ")
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	pkgName, files, err := parseDir(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(pkgName, files, *typeName, *caseInsensitive)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, snakeCase(*typeName)+"_enum.go")
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseDir parses the non-test go files of the package in the directory.
+func parseDir(dir string) (string, []*ast.File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	fset := token.NewFileSet()
+	var pkgName string
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, err
+		}
+		if pkgName == "" {
+			pkgName = f.Name.Name
+		}
+		if f.Name.Name == pkgName {
+			files = append(files, f)
+		}
+	}
+	if pkgName == "" {
+		return "", nil, fmt.Errorf("no go files in %s", dir)
+	}
+	return pkgName, files, nil
+}
+
+type enumConst struct {
+	Name  string
+	Value string
+}
+
+// enumConsts returns the string constants of the type in the order of declaration.
+// The constants must be declared with the type: StatusDraft Status = "draft".
+func enumConsts(files []*ast.File, typeName string) ([]enumConst, error) {
+	var consts []enumConst
+	found := false
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.Name != typeName {
+						continue
+					}
+					if ident, ok := spec.Type.(*ast.Ident); !ok || ident.Name != "string" {
+						return nil, fmt.Errorf("type %s is not a string type", typeName)
+					}
+					found = true
+				case *ast.ValueSpec:
+					if gen.Tok != token.CONST {
+						continue
+					}
+					if ident, ok := spec.Type.(*ast.Ident); !ok || ident.Name != typeName {
+						continue
+					}
+					for i, name := range spec.Names {
+						if i >= len(spec.Values) {
+							return nil, fmt.Errorf("constant %s has no value", name.Name)
+						}
+						lit, ok := spec.Values[i].(*ast.BasicLit)
+						if !ok || lit.Kind != token.STRING {
+							return nil, fmt.Errorf("constant %s is not a string literal", name.Name)
+						}
+						value, err := strconv.Unquote(lit.Value)
+						if err != nil {
+							return nil, err
+						}
+						consts = append(consts, enumConst{name.Name, value})
+					}
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("type %s is not found", typeName)
+	}
+	if len(consts) == 0 {
+		return nil, fmt.Errorf("type %s has no constants", typeName)
+	}
+	// the values are the cases of the switch in Validate so they must be unique
+	names := map[string]string{}
+	for _, c := range consts {
+		if other, ok := names[c.Value]; ok {
+			return nil, fmt.Errorf("constants %s and %s have the same value %q", other, c.Name, c.Value)
+		}
+		names[c.Value] = c.Name
+	}
+	return consts, nil
+}
+
+var enumTemplate = template.Must(template.New("enum").Parse(`// Code generated by geh-enum; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+{{- if .CaseInsensitive}}
+	"strings"
+{{- end}}
+)
+
+// {{.Type}}Values are the allowed values of {{.Type}}.
+var {{.Type}}Values = []{{.Type}}{ {{- range $i, $c := .Consts}}{{if $i}}, {{end}}{{$c.Name}}{{end -}} }
+
+// Parse parses one of {{.Type}}Values{{if .CaseInsensitive}} in any case{{end}}.
+func ({{.Type}}) Parse(ctx context.Context, v string) ({{.Type}}, error) {
+	for _, value := range {{.Type}}Values {
+{{- if .CaseInsensitive}}
+		if strings.EqualFold(string(value), v) {
+{{- else}}
+		if string(value) == v {
+{{- end}}
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("invalid {{.Type}} value: %s, allowed: %s", v, {{printf "%q" .Allowed}})
+}
+
+// Validate checks the value is one of {{.Type}}Values.
+func (v {{.Type}}) Validate() error {
+	switch v {
+	case {{range $i, $c := .Consts}}{{if $i}}, {{end}}{{$c.Name}}{{end}}:
+		return nil
+	}
+	return fmt.Errorf("invalid {{.Type}} value: %s, allowed: %s", string(v), {{printf "%q" .Allowed}})
+}
+
+// EnumValues returns the allowed values. They are reported in the OpenAPI schema.
+func ({{.Type}}) EnumValues() []string {
+	return []string{ {{- range $i, $c := .Consts}}{{if $i}}, {{end}}{{printf "%q" $c.Value}}{{end -}} }
+}
+`))
+
+// generate returns the formatted source of the methods for the enum type.
+func generate(pkgName string, files []*ast.File, typeName string, caseInsensitive bool) ([]byte, error) {
+	consts, err := enumConsts(files, typeName)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make([]string, len(consts))
+	for i, c := range consts {
+		allowed[i] = c.Value
+	}
+	var buf bytes.Buffer
+	err = enumTemplate.Execute(&buf, map[string]any{
+		"Package":         pkgName,
+		"Type":            typeName,
+		"Consts":          consts,
+		"CaseInsensitive": caseInsensitive,
+		"Allowed":         strings.Join(allowed, ", "),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// snakeCase converts BookStatus to book_status. Runs of capitals are kept together: HTTPStatus is http_status.
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
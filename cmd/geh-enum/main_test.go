@@ -0,0 +1,102 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const enumSource = `package books
+
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusPublished Status = "published"
+	defaultLimit           = 20
+)
+
+const StatusArchived Status = "archived"
+`
+
+func parseSource(t *testing.T, src string) []*ast.File {
+	f, err := parser.ParseFile(token.NewFileSet(), "books.go", src, parser.SkipObjectResolution)
+	require.NoError(t, err)
+	return []*ast.File{f}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("books", parseSource(t, enumSource), "Status", true)
+	require.NoError(t, err)
+
+	expected := `// Code generated by geh-enum; DO NOT EDIT.
+
+package books
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// StatusValues are the allowed values of Status.
+var StatusValues = []Status{StatusDraft, StatusPublished, StatusArchived}
+
+// Parse parses one of StatusValues in any case.
+func (Status) Parse(ctx context.Context, v string) (Status, error) {
+	for _, value := range StatusValues {
+		if strings.EqualFold(string(value), v) {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("invalid Status value: %s, allowed: %s", v, "draft, published, archived")
+}
+
+// Validate checks the value is one of StatusValues.
+func (v Status) Validate() error {
+	switch v {
+	case StatusDraft, StatusPublished, StatusArchived:
+		return nil
+	}
+	return fmt.Errorf("invalid Status value: %s, allowed: %s", string(v), "draft, published, archived")
+}
+
+// EnumValues returns the allowed values. They are reported in the OpenAPI schema.
+func (Status) EnumValues() []string {
+	return []string{"draft", "published", "archived"}
+}
+`
+	require.Equal(t, expected, string(src))
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	testCases := []struct {
+		src      string
+		typeName string
+		err      string
+	}{
+		{enumSource, "Format", "type Format is not found"},
+		{"package books\ntype Status int\n", "Status", "type Status is not a string type"},
+		{"package books\ntype Status string\n", "Status", "type Status has no constants"},
+		{"package books\ntype Status string\nconst StatusDraft Status = \"d\" + \"raft\"\n", "Status", "constant StatusDraft is not a string literal"},
+		{"package books\ntype Status string\nconst (\nStatusDraft Status = \"draft\"\nStatusNew Status = \"draft\"\n)\n", "Status",
+			`constants StatusDraft and StatusNew have the same value "draft"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.err, func(t *testing.T) {
+			_, err := generate("books", parseSource(t, tc.src), tc.typeName, false)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	require.Equal(t, "book_status", snakeCase("BookStatus"))
+	require.Equal(t, "status", snakeCase("status"))
+	require.Equal(t, "http_status", snakeCase("HTTPStatus"))
+	require.Equal(t, "book_id", snakeCase("BookID"))
+	require.Equal(t, "book_http_status2", snakeCase("BookHTTPStatus2"))
+}
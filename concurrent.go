@@ -0,0 +1,138 @@
+package goergohandler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// concurrentParsers is a parser running the parsers attached to it concurrently.
+type concurrentParsers struct {
+	builder *Builder
+	parsers []ValueParser
+}
+
+func (g *concurrentParsers) AddParser(parser ValueParser) {
+	g.parsers = append(g.parsers, parser)
+}
+
+func (g *concurrentParsers) VarsGetter() VarsGetter {
+	return g.builder.VarsGetter()
+}
+
+// maxBodySize returns the biggest body size limit of the parsers reading the body.
+// Zero means one of the parsers is not limited. The returned bool is false if none of the parsers reads the body.
+func (g *concurrentParsers) maxBodySize() (int64, bool) {
+	var limit int64
+	found := false
+	for _, p := range g.parsers {
+		br, ok := p.(bodyReader)
+		if !ok {
+			continue
+		}
+		found = true
+		n := br.maxBodySize()
+		if n <= 0 {
+			return 0, true
+		}
+		limit = max(limit, n)
+	}
+	return limit, found
+}
+
+// ParseRequest runs the parsers concurrently. The values the parsers put into the context are merged.
+// The first failure cancels the context passed to the other parsers and its error is returned.
+// The multipart parsers run first one by one on the original body and share the parsed form.
+// If other parsers read the body, it is read into memory before they start and every parser
+// gets its own reader over it.
+func (g *concurrentParsers) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	results := make([]context.Context, len(g.parsers))
+	for i, p := range g.parsers {
+		if _, ok := p.(multipartReader); !ok {
+			continue
+		}
+		newctx, err := p.ParseRequest(ctx, w, r)
+		if err != nil {
+			return ctx, err
+		}
+		results[i] = newctx
+	}
+
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var body []byte
+	limit, readsBody := g.maxBodySize()
+	if readsBody {
+		var err error
+		body, err = bufferBody(w, r, limit)
+		if err != nil {
+			return ctx, bodyReadError(err)
+		}
+		r.Body = newBufferedBody(body)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for i, p := range g.parsers {
+		if _, ok := p.(multipartReader); ok {
+			continue
+		}
+		req := r.WithContext(groupCtx)
+		if readsBody {
+			req.Body = newBufferedBody(body)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			newctx, err := p.ParseRequest(groupCtx, w, req)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = newctx
+		}()
+	}
+	wg.Wait()
+
+	if readsBody {
+		r.Body = newBufferedBody(body)
+	}
+
+	if firstErr != nil {
+		return ctx, firstErr
+	}
+	return &mergedContext{ctx, results}, nil
+}
+
+func (g *concurrentParsers) Describe() []ParamDescription {
+	var params []ParamDescription
+	for _, p := range g.parsers {
+		params = append(params, DescribeParser(p)...)
+	}
+	return params
+}
+
+// mergedContext is the parent context with the values of the contexts returned by the concurrent parsers.
+// Cancellation and deadline are taken from the parent.
+type mergedContext struct {
+	context.Context
+	layers []context.Context
+}
+
+func (c *mergedContext) Value(key any) any {
+	// layers are derived from the parent so they also return the parent values
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		if v := c.layers[i].Value(key); v != nil {
+			return v
+		}
+	}
+	return c.Context.Value(key)
+}
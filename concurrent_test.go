@@ -0,0 +1,143 @@
+package goergohandler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type slowValueKey string
+
+// slowParser puts the value into the context after the delay or fails with err.
+type slowParser struct {
+	key       slowValueKey
+	delay     time.Duration
+	err       error
+	cancelled *atomic.Bool
+}
+
+func (p *slowParser) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		if p.cancelled != nil {
+			p.cancelled.Store(true)
+		}
+		return ctx, ctx.Err()
+	}
+	if p.err != nil {
+		return ctx, p.err
+	}
+	return context.WithValue(ctx, p.key, string(p.key)), nil
+}
+
+func TestBuilder_Concurrent(t *testing.T) {
+	builder := geh.New()
+	limit := geh.QueryParamInt("limit").Attach(builder)
+	group := builder.Concurrent()
+	group.AddParser(&slowParser{key: "first", delay: 50 * time.Millisecond})
+	group.AddParser(&slowParser{key: "second", delay: 50 * time.Millisecond})
+	payload := geh.Payload[testPayload]().Attach(group)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		w.Write([]byte(strings.Join([]string{
+			ctx.Value(slowValueKey("first")).(string),
+			ctx.Value(slowValueKey("second")).(string),
+			payload.Get(r).SomeKey,
+		}, " ")))
+		require.Equal(t, 10, limit.Get(r))
+	})
+
+	started := time.Now()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/?limit=10", strings.NewReader(`{"some_key":"value"}`)))
+	require.Equal(t, "first second value", w.Body.String())
+	require.Less(t, time.Since(started), 100*time.Millisecond)
+	require.Len(t, builder.Describe(), 2)
+}
+
+func TestBuilder_Concurrent_FailureCancelsOthers(t *testing.T) {
+	var cancelled atomic.Bool
+
+	builder := geh.New()
+	group := builder.Concurrent()
+	group.AddParser(&slowParser{key: "slow", delay: time.Second, cancelled: &cancelled})
+	group.AddParser(&slowParser{key: "failing", delay: 10 * time.Millisecond, err: geh.NewError(http.StatusForbidden, errors.New("forbidden"))})
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not be called")
+	})
+
+	started := time.Now()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, `{"error":"forbidden"}`, w.Body.String())
+	require.True(t, cancelled.Load())
+	require.Less(t, time.Since(started), 500*time.Millisecond)
+}
+
+type otherPayload struct {
+	OtherKey string `json:"other_key"`
+}
+
+func TestBuilder_Concurrent_BodyParsers(t *testing.T) {
+	body := `{"some_key":"value","other_key":"other"}`
+
+	t.Run("payload in the group and on the builder", func(t *testing.T) {
+		builder := geh.New()
+		first := geh.Payload[testPayload]().Attach(builder.Concurrent())
+		second := geh.Payload[otherPayload]().Attach(builder)
+
+		handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(first.Get(r).SomeKey + " " + second.Get(r).OtherKey))
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "value other", w.Body.String())
+	})
+
+	t.Run("two payloads in the group", func(t *testing.T) {
+		builder := geh.New()
+		group := builder.Concurrent()
+		first := geh.Payload[testPayload]().Attach(group)
+		second := geh.Payload[otherPayload]().Attach(group)
+
+		handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(first.Get(r).SomeKey + " " + second.Get(r).OtherKey))
+		})
+
+		for range 20 {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, "value other", w.Body.String())
+		}
+	})
+
+	t.Run("body over the limit of the group", func(t *testing.T) {
+		builder := geh.New()
+		group := builder.Concurrent()
+		geh.Payload[testPayload]().WithMaxBodySize(8).Attach(group)
+		geh.Payload[otherPayload]().WithMaxBodySize(16).Attach(group)
+
+		handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler must not be called")
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
@@ -0,0 +1,216 @@
+package goergohandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	defaultHttpStatusCodeErrCookieMissing    = http.StatusBadRequest
+	defaultHttpStatusCodeErrCookieParsing    = http.StatusBadRequest
+	defaultHttpStatusCodeErrCookieValidation = http.StatusBadRequest
+	defaultHttpStatusCodeErrCookieMalformed  = http.StatusBadRequest
+	defaultHttpStatusCodeErrCookieTampered   = http.StatusUnauthorized
+	defaultHttpStatusCodeErrCookieExpired    = http.StatusUnauthorized
+)
+
+var (
+	ErrCookieMissing = errors.New("required cookie is missing")
+)
+
+// cookieValue returns the value of the cookie decoded with the codec if it is not nil.
+func cookieValue(r *http.Request, name string, codec CookieCodec) (string, bool, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", false, nil
+	}
+	if codec == nil {
+		return cookie.Value, true, nil
+	}
+	value, err := codec.Decode(name, cookie.Value)
+	if err != nil {
+		status := defaultHttpStatusCodeErrCookieMalformed
+		if errors.Is(err, ErrCookieTampered) {
+			status = defaultHttpStatusCodeErrCookieTampered
+		} else if errors.Is(err, ErrCookieExpired) {
+			status = defaultHttpStatusCodeErrCookieExpired
+		}
+		return "", true, WrapWithStatusCode(fmt.Errorf("%w: %s", err, name), status)
+	}
+	return value, true, nil
+}
+
+func describeCookie[T any](name string, required bool, codec CookieCodec) ParamDescription {
+	statuses := []int{defaultHttpStatusCodeErrCookieParsing, defaultHttpStatusCodeErrCookieValidation}
+	if required {
+		statuses = append(statuses, defaultHttpStatusCodeErrCookieMissing)
+	}
+	if codec != nil {
+		statuses = append(statuses,
+			defaultHttpStatusCodeErrCookieMalformed,
+			defaultHttpStatusCodeErrCookieTampered,
+			defaultHttpStatusCodeErrCookieExpired,
+		)
+	}
+	return ParamDescription{
+		Location:      ParamLocationCookie,
+		Name:          name,
+		Type:          typeOf[T](),
+		Required:      required,
+		ErrorStatuses: uniqueStatuses(statuses...),
+	}
+}
+
+type CookieParserFunc[T any] func(ctx context.Context, v string) (T, error)
+
+type CookieType[T any] struct {
+	Name       string
+	Parser     CookieParserFunc[T]
+	ErrMissing error
+	codec      CookieCodec
+}
+
+// Cookie is a parser that parses a required cookie from the request.
+// If the cookie is missing, it returns ErrCookieMissing.
+// If the type implements WithValidation, it will be validated.
+func Cookie[T any](name string, parser CookieParserFunc[T]) *CookieType[T] {
+	return &CookieType[T]{
+		Name:   name,
+		Parser: parser,
+	}
+}
+
+// WithMissingError sets the error to be returned if the cookie is missing.
+func (c *CookieType[T]) WithMissingError(err error) *CookieType[T] {
+	c.ErrMissing = err
+	return c
+}
+
+// WithCodec makes the parser decode the cookie with the codec before parsing, e.g. SignedCookieCodec
+// or EncryptedCookieCodec. Cookies failing the integrity check are rejected with ErrCookieTampered and 401 status code,
+// expired cookies with ErrCookieExpired and 401 status code, malformed cookies with ErrCookieMalformed and 400 status code. Use EncodeCookie to set the cookie.
+func (c *CookieType[T]) WithCodec(codec CookieCodec) *CookieType[T] {
+	c.codec = codec
+	return c
+}
+
+func (c *CookieType[T]) Attach(b ParserAdder) *AttachedCookie[T] {
+	a := &AttachedCookie[T]{c}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedCookie[T any] struct {
+	c *CookieType[T]
+}
+
+func (p *AttachedCookie[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	vstr, ok, err := cookieValue(r, p.c.Name, p.c.codec)
+	if err != nil {
+		return ctx, err
+	}
+	if !ok {
+		err := p.c.ErrMissing
+		if err == nil {
+			err = fmt.Errorf("%w: %s", ErrCookieMissing, p.c.Name)
+		}
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrCookieMissing)
+	}
+	v, err := p.c.Parser(ctx, vstr)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrCookieParsing)
+	}
+	err = ValidateWithValidation(v)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrCookieValidation)
+	}
+	return context.WithValue(ctx, p, v), nil
+}
+
+func (p *AttachedCookie[T]) Describe() []ParamDescription {
+	return []ParamDescription{describeCookie[T](p.c.Name, true, p.c.codec)}
+}
+
+func (p *AttachedCookie[T]) Get(r *http.Request) T {
+	return p.GetContext(r.Context())
+}
+
+func (p *AttachedCookie[T]) GetContext(ctx context.Context) T {
+	return GetFromContext[T](ctx, p)
+}
+
+type CookieMaybeType[T any] struct {
+	Name   string
+	Parser CookieParserFunc[T]
+	codec  CookieCodec
+}
+
+// CookieMaybe is same as Cookie but it doesn't return an error if the cookie is missing.
+// Cookies failing the codec checks are still rejected.
+func CookieMaybe[T any](name string, parser CookieParserFunc[T]) *CookieMaybeType[T] {
+	return &CookieMaybeType[T]{
+		Name:   name,
+		Parser: parser,
+	}
+}
+
+// WithCodec makes the parser decode the cookie with the codec before parsing. See CookieType.WithCodec.
+func (c *CookieMaybeType[T]) WithCodec(codec CookieCodec) *CookieMaybeType[T] {
+	c.codec = codec
+	return c
+}
+
+func (c *CookieMaybeType[T]) Attach(b ParserAdder) *AttachedCookieMaybe[T] {
+	a := &AttachedCookieMaybe[T]{c}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedCookieMaybe[T any] struct {
+	c *CookieMaybeType[T]
+}
+
+func (p *AttachedCookieMaybe[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	vstr, ok, err := cookieValue(r, p.c.Name, p.c.codec)
+	if err != nil {
+		return ctx, err
+	}
+	if !ok {
+		return ctx, nil
+	}
+	v, err := p.c.Parser(ctx, vstr)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrCookieParsing)
+	}
+	err = ValidateWithValidation(v)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrCookieValidation)
+	}
+	return context.WithValue(ctx, p, v), nil
+}
+
+func (p *AttachedCookieMaybe[T]) Describe() []ParamDescription {
+	return []ParamDescription{describeCookie[T](p.c.Name, false, p.c.codec)}
+}
+
+func (p *AttachedCookieMaybe[T]) GetMaybe(r *http.Request) (*T, bool) {
+	return p.GetContextMaybe(r.Context())
+}
+
+func (p *AttachedCookieMaybe[T]) GetDefault(r *http.Request, defaultVal T) T {
+	return p.GetContextDefault(r.Context(), defaultVal)
+}
+
+func (p *AttachedCookieMaybe[T]) GetContextDefault(ctx context.Context, defaultVal T) T {
+	v, ok := p.GetContextMaybe(ctx)
+	if !ok {
+		return defaultVal
+	}
+	return *v
+}
+
+func (p *AttachedCookieMaybe[T]) GetContextMaybe(ctx context.Context) (*T, bool) {
+	return GetFromContextMaybe[T](ctx, p)
+}
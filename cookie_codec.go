@@ -0,0 +1,199 @@
+package goergohandler
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Returned by the codecs when the cookie value is not produced by them.
+	ErrCookieMalformed = errors.New("cookie is malformed")
+	// Returned by the codecs when the signature of the cookie does not match any of the keys.
+	ErrCookieTampered = errors.New("cookie is tampered")
+	// Returned by the codecs with the max age when the cookie is older.
+	ErrCookieExpired = errors.New("cookie is expired")
+	ErrCookieNoKeys  = errors.New("cookie codec needs at least one key")
+)
+
+// CookieCodec encodes the cookie values set by the server and decodes them back.
+// The name of the cookie is bound to the value, so it cannot be moved into another cookie.
+type CookieCodec interface {
+	Encode(name, value string) (string, error)
+	// Decode returns ErrCookieMalformed or ErrCookieTampered if the value cannot be trusted.
+	Decode(name, encoded string) (string, error)
+}
+
+// EncodeCookie returns the copy of the cookie with the value encoded by the codec.
+// Use it to set the cookies read by the parsers with the same codec:
+//
+//	cookie, err := geh.EncodeCookie(codec, &http.Cookie{Name: "session", Value: sessionID, HttpOnly: true})
+//	return geh.NewResponseWithCookies(result, cookie), err
+func EncodeCookie(codec CookieCodec, cookie *http.Cookie) (*http.Cookie, error) {
+	c := *cookie
+	if codec == nil {
+		return &c, nil
+	}
+	value, err := codec.Encode(c.Name, c.Value)
+	if err != nil {
+		return nil, err
+	}
+	c.Value = value
+	return &c, nil
+}
+
+// SignedCookieCodec signs the values with HMAC-SHA256. The values are readable by the client.
+type SignedCookieCodec struct {
+	keys   [][]byte
+	maxAge time.Duration
+}
+
+// NewSignedCookieCodec creates the codec signing with the first key. All the keys are accepted
+// when the signature is checked, so the keys can be rotated by prepending the new one.
+func NewSignedCookieCodec(keys ...[]byte) (*SignedCookieCodec, error) {
+	if len(keys) == 0 {
+		return nil, ErrCookieNoKeys
+	}
+	return &SignedCookieCodec{keys: keys}, nil
+}
+
+// WithMaxAge makes the codec sign the issue time along with the value and reject the values issued
+// more than maxAge ago with ErrCookieExpired. The values encoded without the issue time are malformed for this codec.
+// Unlike the MaxAge of the cookie, the limit cannot be bypassed by the client keeping the cookie.
+func (c *SignedCookieCodec) WithMaxAge(maxAge time.Duration) *SignedCookieCodec {
+	c.maxAge = maxAge
+	return c
+}
+
+func (c *SignedCookieCodec) Encode(name, value string) (string, error) {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(value))
+	issuedAt := ""
+	if c.maxAge > 0 {
+		issuedAt = strconv.FormatInt(time.Now().Unix(), 10)
+		encoded += "." + issuedAt
+	}
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(c.keys[0], name, value, issuedAt)), nil
+}
+
+func (c *SignedCookieCodec) Decode(name, encoded string) (string, error) {
+	parts := strings.Split(encoded, ".")
+	issuedAt := ""
+	if c.maxAge > 0 && len(parts) == 3 {
+		issuedAt = parts[1]
+		parts = []string{parts[0], parts[2]}
+	}
+	if len(parts) != 2 {
+		return "", ErrCookieMalformed
+	}
+	value, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrCookieMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrCookieMalformed
+	}
+	if c.maxAge > 0 && issuedAt == "" {
+		return "", ErrCookieMalformed
+	}
+	for _, key := range c.keys {
+		if hmac.Equal(sig, c.sign(key, name, string(value), issuedAt)) {
+			if err := c.checkAge(issuedAt); err != nil {
+				return "", err
+			}
+			return string(value), nil
+		}
+	}
+	return "", ErrCookieTampered
+}
+
+// checkAge checks the signed issue time against the max age.
+func (c *SignedCookieCodec) checkAge(issuedAt string) error {
+	if c.maxAge <= 0 {
+		return nil
+	}
+	sec, err := strconv.ParseInt(issuedAt, 10, 64)
+	if err != nil {
+		return ErrCookieMalformed
+	}
+	if time.Since(time.Unix(sec, 0)) > c.maxAge {
+		return ErrCookieExpired
+	}
+	return nil
+}
+
+// sign returns the signature of the name, the value and the issue time which is empty without the max age.
+func (c *SignedCookieCodec) sign(key []byte, name, value, issuedAt string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	if issuedAt != "" {
+		mac.Write([]byte{0})
+		mac.Write([]byte(issuedAt))
+	}
+	return mac.Sum(nil)
+}
+
+// EncryptedCookieCodec encrypts the values with AES-GCM. The values are neither readable nor modifiable by the client.
+type EncryptedCookieCodec struct {
+	aeads []cipher.AEAD
+}
+
+// NewEncryptedCookieCodec creates the codec encrypting with the first key. All the keys are tried
+// when the value is decrypted, so the keys can be rotated by prepending the new one.
+// The keys must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+func NewEncryptedCookieCodec(keys ...[]byte) (*EncryptedCookieCodec, error) {
+	if len(keys) == 0 {
+		return nil, ErrCookieNoKeys
+	}
+	c := &EncryptedCookieCodec{}
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.aeads = append(c.aeads, aead)
+	}
+	return c, nil
+}
+
+func (c *EncryptedCookieCodec) Encode(name, value string) (string, error) {
+	aead := c.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (c *EncryptedCookieCodec) Decode(name, encoded string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrCookieMalformed
+	}
+	for _, aead := range c.aeads {
+		if len(data) < aead.NonceSize()+aead.Overhead() {
+			return "", ErrCookieMalformed
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		value, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+		if err == nil {
+			return string(value), nil
+		}
+	}
+	return "", ErrCookieTampered
+}
@@ -0,0 +1,191 @@
+package goergohandler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+var identityParser = geh.IgnoreContext(func(s string) (string, error) { return s, nil })
+
+func serveWithCookies(handler http.Handler, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestCookie(t *testing.T) {
+	builder := geh.New()
+	page := geh.Cookie("page_size", geh.IgnoreContext(strconv.Atoi)).Attach(builder)
+	theme := geh.CookieMaybe("theme", identityParser).Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strconv.Itoa(page.Get(r)) + ":" + theme.GetDefault(r, "light")))
+	})
+
+	w := serveWithCookies(handler, &http.Cookie{Name: "page_size", Value: "20"})
+	require.Equal(t, "20:light", w.Body.String())
+
+	w = serveWithCookies(handler, &http.Cookie{Name: "page_size", Value: "20"}, &http.Cookie{Name: "theme", Value: "dark"})
+	require.Equal(t, "20:dark", w.Body.String())
+
+	w = serveWithCookies(handler)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"required cookie is missing: page_size"}`, w.Body.String())
+
+	require.Equal(t, "cookie page_size int required", builder.Describe()[0].String())
+}
+
+func TestCookie_Codecs(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	signedOld, err := geh.NewSignedCookieCodec(oldKey)
+	require.NoError(t, err)
+	signed, err := geh.NewSignedCookieCodec(newKey, oldKey)
+	require.NoError(t, err)
+	encryptedOld, err := geh.NewEncryptedCookieCodec(oldKey)
+	require.NoError(t, err)
+	encrypted, err := geh.NewEncryptedCookieCodec(newKey, oldKey)
+	require.NoError(t, err)
+
+	_, err = geh.NewEncryptedCookieCodec([]byte("short"))
+	require.Error(t, err)
+	_, err = geh.NewSignedCookieCodec()
+	require.ErrorIs(t, err, geh.ErrCookieNoKeys)
+
+	testCases := []struct {
+		name  string
+		codec geh.CookieCodec
+		old   geh.CookieCodec
+	}{
+		{"signed", signed, signedOld},
+		{"encrypted", encrypted, encryptedOld},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := geh.New()
+			session := geh.Cookie("session", identityParser).WithCodec(tc.codec).Attach(builder)
+			handler := builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+				cookie, err := geh.EncodeCookie(tc.codec, &http.Cookie{Name: "session", Value: session.Get(r) + "-renewed"})
+				return geh.NewResponseWithCookies(geh.NewResponseWithHttpStatus(http.StatusCreated, session.Get(r)), cookie), err
+			})
+
+			cookie, err := geh.EncodeCookie(tc.codec, &http.Cookie{Name: "session", Value: "user-1"})
+			require.NoError(t, err)
+			require.NotEqual(t, "user-1", cookie.Value)
+
+			w := serveWithCookies(handler, cookie)
+			require.Equal(t, http.StatusCreated, w.Code)
+			require.Equal(t, `{"result":"user-1"}`, w.Body.String())
+
+			// the response cookie is readable by the parser
+			renewed := w.Result().Cookies()
+			require.Len(t, renewed, 1)
+			w = serveWithCookies(handler, renewed[0])
+			require.Equal(t, `{"result":"user-1-renewed"}`, w.Body.String())
+
+			// the cookie encoded with the rotated out key is still accepted
+			cookie, err = geh.EncodeCookie(tc.old, &http.Cookie{Name: "session", Value: "user-2"})
+			require.NoError(t, err)
+			w = serveWithCookies(handler, cookie)
+			require.Equal(t, `{"result":"user-2"}`, w.Body.String())
+
+			// tampered value
+			tampered := *cookie
+			if tampered.Value[0] == 'A' {
+				tampered.Value = "B" + tampered.Value[1:]
+			} else {
+				tampered.Value = "A" + tampered.Value[1:]
+			}
+			w = serveWithCookies(handler, &tampered)
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+			require.Equal(t, `{"error":"cookie is tampered: session"}`, w.Body.String())
+
+			// the value moved into another cookie
+			other, err := geh.EncodeCookie(tc.codec, &http.Cookie{Name: "other", Value: "user-1"})
+			require.NoError(t, err)
+			other.Name = "session"
+			w = serveWithCookies(handler, other)
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+
+			w = serveWithCookies(handler, &http.Cookie{Name: "session", Value: "user-1"})
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Equal(t, `{"error":"cookie is malformed: session"}`, w.Body.String())
+		})
+	}
+}
+
+func TestResponseWithCookies_Nested(t *testing.T) {
+	handler := geh.New().BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return geh.NewResponseWithHttpStatus(http.StatusCreated,
+			geh.NewResponseWithCookies(
+				geh.NewResponseWithCookies("ok", &http.Cookie{Name: "inner", Value: "2"}),
+				&http.Cookie{Name: "outer", Value: "1"},
+			),
+		), nil
+	})
+
+	w := serveWithCookies(handler)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, `{"result":"ok"}`, w.Body.String())
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 2)
+	require.Equal(t, "outer", cookies[0].Name)
+	require.Equal(t, "inner", cookies[1].Name)
+}
+
+func TestSignedCookieCodec_MaxAge(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	codec, err := geh.NewSignedCookieCodec(key)
+	require.NoError(t, err)
+	codec.WithMaxAge(time.Hour)
+
+	encoded, err := codec.Encode("session", "user-1")
+	require.NoError(t, err)
+	value, err := codec.Decode("session", encoded)
+	require.NoError(t, err)
+	require.Equal(t, "user-1", value)
+
+	// the issue time is signed
+	valuePart, rest, _ := strings.Cut(encoded, ".")
+	_, sig, _ := strings.Cut(rest, ".")
+	_, err = codec.Decode("session", valuePart+".9999999999."+sig)
+	require.ErrorIs(t, err, geh.ErrCookieTampered)
+
+	// the values without the issue time are not accepted
+	withoutMaxAge, err := geh.NewSignedCookieCodec(key)
+	require.NoError(t, err)
+	plain, err := withoutMaxAge.Encode("session", "user-1")
+	require.NoError(t, err)
+	_, err = codec.Decode("session", plain)
+	require.ErrorIs(t, err, geh.ErrCookieMalformed)
+
+	expiring, err := geh.NewSignedCookieCodec(key)
+	require.NoError(t, err)
+	expiring.WithMaxAge(time.Nanosecond)
+	encoded, err = expiring.Encode("session", "user-1")
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = expiring.Decode("session", encoded)
+	require.ErrorIs(t, err, geh.ErrCookieExpired)
+
+	builder := geh.New()
+	geh.Cookie("session", identityParser).WithCodec(expiring).Attach(builder)
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	w := serveWithCookies(handler, &http.Cookie{Name: "session", Value: encoded})
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, `{"error":"cookie is expired: session"}`, w.Body.String())
+}
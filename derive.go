@@ -0,0 +1,96 @@
+package goergohandler
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	defaultHttpStatusCodeErrDerivedValidation = http.StatusBadRequest
+)
+
+// ContextGetter is implemented by the attached parsers returning a value from the context.
+type ContextGetter[T any] interface {
+	GetContext(ctx context.Context) T
+}
+
+// DeriveFunc computes a value from the values of the other attached parsers.
+type DeriveFunc[T any] func(ctx context.Context) (T, error)
+
+type DeriveType[T any] struct {
+	f DeriveFunc[T]
+}
+
+// Derive makes a parser computing the value with f. Use Derive1, Derive2 and Derive3 to compute
+// the value from the values of other attached parsers.
+// Errors returned by f are returned as is if they are wrapped with a status code (use NewError to return 404 or 403),
+// otherwise they are wrapped with InternalServerError.
+// If the value implements WithValidation, it will be validated.
+func Derive[T any](f DeriveFunc[T]) *DeriveType[T] {
+	return &DeriveType[T]{f: f}
+}
+
+// Derive1 makes a parser computing the value from the value of an attached parser.
+// The parser must be attached after its input.
+func Derive1[A, T any](a ContextGetter[A], f func(ctx context.Context, a A) (T, error)) *DeriveType[T] {
+	return Derive(func(ctx context.Context) (T, error) {
+		return f(ctx, a.GetContext(ctx))
+	})
+}
+
+// Derive2 makes a parser computing the value from the values of two attached parsers.
+// The parser must be attached after its inputs.
+// Example:
+//
+//	bookID := paramBookID.Attach(builder)
+//	user := authParser.Attach(validator, builder)
+//	book := geh.Derive2(bookID, user, func(ctx context.Context, id paramBookIDType, u *User) (Book, error) {
+//		return useCase.GetBookForUser(ctx, id, u)
+//	}).Attach(builder)
+func Derive2[A, B, T any](a ContextGetter[A], b ContextGetter[B], f func(ctx context.Context, a A, b B) (T, error)) *DeriveType[T] {
+	return Derive(func(ctx context.Context) (T, error) {
+		return f(ctx, a.GetContext(ctx), b.GetContext(ctx))
+	})
+}
+
+// Derive3 makes a parser computing the value from the values of three attached parsers.
+// The parser must be attached after its inputs.
+func Derive3[A, B, C, T any](a ContextGetter[A], b ContextGetter[B], c ContextGetter[C], f func(ctx context.Context, a A, b B, c C) (T, error)) *DeriveType[T] {
+	return Derive(func(ctx context.Context) (T, error) {
+		return f(ctx, a.GetContext(ctx), b.GetContext(ctx), c.GetContext(ctx))
+	})
+}
+
+func (d *DeriveType[T]) Attach(b ParserAdder) *AttachedDerived[T] {
+	a := &AttachedDerived[T]{d}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedDerived[T any] struct {
+	d *DeriveType[T]
+}
+
+func (a *AttachedDerived[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	v, err := a.d.f(ctx)
+	if err != nil {
+		return ctx, NewInternalServerError(err)
+	}
+	err = ValidateWithValidation(v)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrDerivedValidation)
+	}
+	return context.WithValue(ctx, a, v), nil
+}
+
+// dependent marks the parser as depending on the values of other parsers.
+// When the builder collects errors, dependent parsers are skipped after a failure.
+func (a *AttachedDerived[T]) dependent() {}
+
+func (a *AttachedDerived[T]) Get(r *http.Request) T {
+	return a.GetContext(r.Context())
+}
+
+func (a *AttachedDerived[T]) GetContext(ctx context.Context) T {
+	return GetFromContext[T](ctx, a)
+}
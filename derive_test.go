@@ -0,0 +1,90 @@
+package goergohandler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type derivedBook struct {
+	ID    int
+	Owner string
+}
+
+func (b derivedBook) Validate() error {
+	if b.Owner == "" {
+		return errors.New("book has no owner")
+	}
+	return nil
+}
+
+type deriveUser struct {
+	Name string
+}
+
+type deriveTokenValidator struct{}
+
+func (deriveTokenValidator) ValidateToken(ctx context.Context, token string) (*deriveUser, bool, error) {
+	return &deriveUser{Name: token}, true, nil
+}
+
+var errDeriveDatabase = errors.New("database is down")
+
+func TestDerive2(t *testing.T) {
+	builder := geh.New().WithCollectErrors()
+	bookID := geh.QueryParamInt("book_id").Attach(builder)
+	user := geh.AuthParser[deriveUser](describeUserKey("user"), geh.TokenBearerFromHeader).Attach(deriveTokenValidator{}, builder)
+	book := geh.Derive2(bookID, user, func(ctx context.Context, id int, u *deriveUser) (derivedBook, error) {
+		switch id {
+		case 1:
+			return derivedBook{ID: id, Owner: "alice"}, nil
+		case 2:
+			return derivedBook{ID: id}, nil
+		case 3:
+			return derivedBook{}, errDeriveDatabase
+		}
+		return derivedBook{}, geh.NewError(http.StatusNotFound, errors.New("book not found"))
+	}).Attach(builder)
+	owned := geh.Derive2(book, user, func(ctx context.Context, b derivedBook, u *deriveUser) (derivedBook, error) {
+		if b.Owner != u.Name {
+			return b, geh.NewError(http.StatusForbidden, errors.New("not an owner"))
+		}
+		return b, nil
+	}).Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%d %s", owned.Get(r).ID, book.Get(r).Owner)
+	})
+
+	cases := []struct {
+		url          string
+		user         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"/?book_id=1", "alice", http.StatusOK, "1 alice"},
+		{"/?book_id=1", "bob", http.StatusForbidden, `{"error":"not an owner"}`},
+		{"/?book_id=2", "alice", http.StatusBadRequest, `{"error":"book has no owner","details":[{"error":"book has no owner"}]}`},
+		{"/?book_id=3", "alice", http.StatusInternalServerError, `{"error":"internal server error"}`},
+		{"/?book_id=4", "alice", http.StatusNotFound, `{"error":"book not found"}`},
+		// dependent parsers are skipped when the inputs failed
+		{"/?book_id=abc", "alice", http.StatusBadRequest, `{"error":"book_id: invalid int value: abc","details":[{"location":"query","name":"book_id","error":"invalid int value: abc"}]}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url+" "+c.user, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", c.url, nil)
+			r.Header.Set("Authorization", "Bearer "+c.user)
+			handler.ServeHTTP(w, r)
+			require.Equal(t, c.expectedCode, w.Code)
+			require.Equal(t, c.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package goergohandler
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// ParamLocation is the place in the request a parser reads its value from.
+type ParamLocation string
+
+const (
+	ParamLocationQuery  ParamLocation = "query"
+	ParamLocationPath   ParamLocation = "path"
+	ParamLocationHeader ParamLocation = "header"
+	ParamLocationCookie ParamLocation = "cookie"
+	ParamLocationBody   ParamLocation = "body"
+	ParamLocationAuth   ParamLocation = "auth"
+)
+
+// ParamDescription is the metadata a parser reports about a value it extracts from the request.
+type ParamDescription struct {
+	Location ParamLocation
+	// Name of the query/router param or header. For the auth location it is the security scheme name.
+	Name string
+	// Type is the Go type of the parsed value.
+	Type     reflect.Type
+	Required bool
+	// ErrorStatuses are the HTTP status codes the parser responds with by default.
+	ErrorStatuses []int
+	// MediaTypes are the accepted media types of the body.
+	MediaTypes []string
+	// Enum are the allowed values of the param, empty if any value is allowed.
+	Enum []string
+	// AuthScheme is the scheme of the auth location.
+	AuthScheme *AuthScheme
+}
+
+// String returns a short human readable description: "query limit int required".
+func (d ParamDescription) String() string {
+	s := string(d.Location)
+	if d.Name != "" {
+		s += " " + d.Name
+	}
+	if d.Type != nil {
+		s += " " + d.Type.String()
+	}
+	if d.Required {
+		s += " required"
+	} else {
+		s += " optional"
+	}
+	return s
+}
+
+// DescribedParser is implemented by parsers that can describe the values they extract.
+type DescribedParser interface {
+	Describe() []ParamDescription
+}
+
+// DescribeParser returns the description of the parser if it implements DescribedParser.
+func DescribeParser(p ValueParser) []ParamDescription {
+	d, ok := p.(DescribedParser)
+	if !ok {
+		return nil
+	}
+	return d.Describe()
+}
+
+// describedHandler is the handler returned by the builder. It keeps the description of the parsers
+// so the handler can be documented after it has been built.
+type describedHandler struct {
+	http.Handler
+	params []ParamDescription
+}
+
+func (h *describedHandler) Describe() []ParamDescription {
+	return h.params
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// uniqueStatuses returns the statuses without duplicates keeping the order.
+func uniqueStatuses(statuses ...int) []int {
+	result := make([]int, 0, len(statuses))
+	seen := make(map[int]bool, len(statuses))
+	for _, s := range statuses {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, s)
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package goergohandler_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type describeUser struct{}
+
+type describeUserKey string
+
+type describeTokenValidator struct{}
+
+func (describeTokenValidator) ValidateToken(ctx context.Context, token string) (*describeUser, bool, error) {
+	return &describeUser{}, true, nil
+}
+
+func TestBuilder_Describe(t *testing.T) {
+	builder := geh.New()
+	geh.AuthParserMaybe[describeUser](describeUserKey("user"), geh.TokenBearerFromHeader).
+		WithSecurityScheme("session").
+		Attach(describeTokenValidator{}, builder)
+	geh.RouterParamInt64("book_id").Attach(builder)
+	geh.QueryParamInt("limit").Attach(builder)
+	geh.QueryParamWithParserMaybe[paramBookIDWithParserType]("cursor").Attach(builder)
+	geh.Payload[testPayload]().Attach(builder)
+	geh.AttachabaleMiddleware(func(h http.Handler) http.Handler { return h }).Attach(builder)
+
+	params := builder.Describe()
+	require.Equal(t, []geh.ParamDescription{
+		{
+			Location:      geh.ParamLocationAuth,
+			Name:          "session",
+			Type:          reflect.TypeOf(describeUser{}),
+			Required:      false,
+			ErrorStatuses: []int{http.StatusInternalServerError},
+			AuthScheme:    &geh.AuthSchemeBearer,
+		},
+		{
+			Location:      geh.ParamLocationPath,
+			Name:          "book_id",
+			Type:          reflect.TypeOf(int64(0)),
+			Required:      true,
+			ErrorStatuses: []int{http.StatusBadRequest},
+		},
+		{
+			Location:      geh.ParamLocationQuery,
+			Name:          "limit",
+			Type:          reflect.TypeOf(0),
+			Required:      true,
+			ErrorStatuses: []int{http.StatusBadRequest},
+		},
+		{
+			Location:      geh.ParamLocationQuery,
+			Name:          "cursor",
+			Type:          reflect.TypeOf(paramBookIDWithParserType("")),
+			Required:      false,
+			ErrorStatuses: []int{http.StatusBadRequest},
+		},
+		{
+			Location:      geh.ParamLocationBody,
+			Type:          reflect.TypeOf(testPayload{}),
+			Required:      true,
+			ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnsupportedMediaType},
+			MediaTypes:    []string{"application/json", "application/x-www-form-urlencoded", "application/xml", "text/xml"},
+		},
+	}, params)
+
+	require.Equal(t, "query limit int required", params[2].String())
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	require.Equal(t, params, handler.(geh.DescribedParser).Describe())
+}
@@ -0,0 +1,204 @@
+package goergohandler
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultHttpStatusCodeErrNotAcceptable = http.StatusNotAcceptable
+)
+
+var (
+	// Returned when none of the response encoders matches the Accept header.
+	ErrNotAcceptable = errors.New("not acceptable")
+)
+
+// ResponseEncoder encodes the results and the errors into the response body.
+type ResponseEncoder interface {
+	EncodeResult(w io.Writer, result any) error
+	EncodeError(w io.Writer, message string, details any) error
+}
+
+// JSONEncoder encodes the result to {"result": result} and the error to {"error": "error message"}.
+// ResponseWithEnvelopeFields adds its fields next to "result".
+type JSONEncoder struct{}
+
+func (JSONEncoder) EncodeResult(w io.Writer, result any) error {
+	var v any = successResponse{Result: result}
+	if withFields, ok := result.(ResponseWithEnvelopeFields); ok {
+		envelope := map[string]any{}
+		for k, f := range withFields.EnvelopeFields() {
+			envelope[k] = f
+		}
+		envelope["result"] = withFields.EnvelopeResult()
+		v = envelope
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bs)
+	return err
+}
+
+func (JSONEncoder) EncodeError(w io.Writer, message string, details any) error {
+	bs, err := json.Marshal(errorResponse{Error: message, Details: details})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bs)
+	return err
+}
+
+// XMLEncoder encodes the result to <response><result>...</result></response>
+// and the error to <response><error>error message</error></response>.
+// ResponseWithEnvelopeFields adds its fields as elements next to <result>, ordered by name.
+type XMLEncoder struct{}
+
+type xmlResponse struct {
+	XMLName xml.Name   `xml:"response"`
+	Result  any        `xml:"result,omitempty"`
+	Error   string     `xml:"error,omitempty"`
+	Details any        `xml:"details,omitempty"`
+	Fields  []xmlField `xml:",any"`
+}
+
+type xmlField struct {
+	XMLName xml.Name
+	Value   any `xml:",chardata"`
+}
+
+func (XMLEncoder) EncodeResult(w io.Writer, result any) error {
+	resp := xmlResponse{Result: result}
+	if withFields, ok := result.(ResponseWithEnvelopeFields); ok {
+		resp.Result = withFields.EnvelopeResult()
+		fields := withFields.EnvelopeFields()
+		for _, k := range slices.Sorted(maps.Keys(fields)) {
+			resp.Fields = append(resp.Fields, xmlField{XMLName: xml.Name{Local: k}, Value: fmt.Sprint(fields[k])})
+		}
+	}
+	return xml.NewEncoder(w).Encode(resp)
+}
+
+func (XMLEncoder) EncodeError(w io.Writer, message string, details any) error {
+	return xml.NewEncoder(w).Encode(xmlResponse{Error: message, Details: details})
+}
+
+const (
+	mediaTypeJSON = "application/json"
+	mediaTypeXML  = "application/xml"
+)
+
+// defaultResponseEncoder is used when no encoder was negotiated.
+var defaultResponseEncoder = mediaTypeEncoder{mediaTypeJSON, JSONEncoder{}}
+
+type mediaTypeEncoder struct {
+	mediaType string
+	encoder   ResponseEncoder
+}
+
+type responseEncoderKeyType struct{}
+
+var responseEncoderKey responseEncoderKeyType
+
+// ResponseEncoderFromContext returns the encoder negotiated for the request and its media type.
+// If the builder has no encoders registered, the JSON encoder is returned.
+func ResponseEncoderFromContext(ctx context.Context) (ResponseEncoder, string) {
+	e, ok := ctx.Value(responseEncoderKey).(mediaTypeEncoder)
+	if !ok {
+		e = defaultResponseEncoder
+	}
+	return e.encoder, e.mediaType
+}
+
+// negotiate returns the encoder matching the Accept header. The first encoder is used
+// if the header is empty or accepts any type.
+func negotiate(encoders []mediaTypeEncoder, accept string) (mediaTypeEncoder, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return encoders[0], true
+	}
+	for _, ar := range parseAccept(accept) {
+		for _, e := range encoders {
+			if ar.matches(e.mediaType) {
+				return e, true
+			}
+		}
+	}
+	return mediaTypeEncoder{}, false
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func (a acceptRange) matches(mediaType string) bool {
+	if a.mediaType == "*/*" || a.mediaType == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(a.mediaType, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// parseAccept parses the Accept header into the media ranges ordered by preference.
+// Ranges with q=0 are dropped.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		ar := acceptRange{mediaType: strings.ToLower(strings.TrimSpace(mediaType)), q: 1}
+		for _, param := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if k == "q" {
+				if q, err := strconv.ParseFloat(v, 64); err == nil {
+					ar.q = q
+				}
+			}
+		}
+		if ar.mediaType == "" || ar.q <= 0 {
+			continue
+		}
+		ranges = append(ranges, ar)
+	}
+	slices.SortStableFunc(ranges, func(a, b acceptRange) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+	return ranges
+}
+
+// negotiatingMiddleware puts the encoder matching the Accept header into the context.
+// If nothing matches it responds with 406 using the first encoder.
+func negotiatingMiddleware(encoders []mediaTypeEncoder, handlerErrorFunc HandleErrorFunc) MiddlewareFunc {
+	if handlerErrorFunc == nil {
+		handlerErrorFunc = DefaultHandlerErrorFunc
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept")
+			e, ok := negotiate(encoders, r.Header.Get("Accept"))
+			if !ok {
+				ctx := context.WithValue(r.Context(), responseEncoderKey, encoders[0])
+				handlerErrorFunc(ctx, w, r.WithContext(ctx), WrapWithStatusCode(ErrNotAcceptable, defaultHttpStatusCodeErrNotAcceptable))
+				return
+			}
+			ctx := context.WithValue(r.Context(), responseEncoderKey, e)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
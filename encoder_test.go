@@ -0,0 +1,209 @@
+package goergohandler_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type csvEncoder struct{}
+
+func (csvEncoder) EncodeResult(w io.Writer, result any) error {
+	cw := csv.NewWriter(w)
+	for _, b := range result.([]encoderBook) {
+		if err := cw.Write([]string{b.Title, fmt.Sprint(b.Price)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (csvEncoder) EncodeError(w io.Writer, message string, details any) error {
+	_, err := io.WriteString(w, "error,"+message+"\n")
+	return err
+}
+
+type encoderBook struct {
+	Title string `json:"title" xml:"title"`
+	Price int    `json:"price" xml:"price"`
+}
+
+func TestBuilder_WithResponseEncoder(t *testing.T) {
+	builder := geh.New().
+		WithResponseEncoder("application/xml", geh.XMLEncoder{}).
+		WithResponseEncoder("text/csv", csvEncoder{})
+	limit := geh.QueryParamIntMaybe("limit").Attach(builder)
+
+	handler := builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		if limit.GetDefault(r, 10) == 0 {
+			return nil, geh.NewError(http.StatusBadRequest, errors.New("zero limit"))
+		}
+		return []encoderBook{{"Dune", 10}, {"Emma", 20}}, nil
+	})
+
+	cases := []struct {
+		name                string
+		url                 string
+		accept              string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "no accept header",
+			url:                 "/",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `{"result":[{"title":"Dune","price":10},{"title":"Emma","price":20}]}`,
+		},
+		{
+			name:                "any",
+			url:                 "/",
+			accept:              "*/*",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `{"result":[{"title":"Dune","price":10},{"title":"Emma","price":20}]}`,
+		},
+		{
+			name:                "xml",
+			url:                 "/",
+			accept:              "application/xml",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/xml",
+			expectedBody:        `<response><result><title>Dune</title><price>10</price></result><result><title>Emma</title><price>20</price></result></response>`,
+		},
+		{
+			name:                "csv preferred by quality",
+			url:                 "/",
+			accept:              "application/json;q=0.5, text/*",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody:        "Dune,10\nEmma,20\n",
+		},
+		{
+			name:                "error in xml",
+			url:                 "/?limit=0",
+			accept:              "application/xml",
+			expectedCode:        http.StatusBadRequest,
+			expectedContentType: "application/xml",
+			expectedBody:        `<response><error>zero limit</error></response>`,
+		},
+		{
+			name:                "parser error in csv",
+			url:                 "/?limit=abc",
+			accept:              "text/csv",
+			expectedCode:        http.StatusBadRequest,
+			expectedContentType: "text/csv",
+			expectedBody:        "error,limit: invalid int value: abc\n",
+		},
+		{
+			name:                "not acceptable",
+			url:                 "/",
+			accept:              "image/png",
+			expectedCode:        http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"not acceptable"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", c.url, nil)
+			if c.accept != "" {
+				r.Header.Set("Accept", c.accept)
+			}
+			handler.ServeHTTP(w, r)
+			require.Equal(t, c.expectedCode, w.Code)
+			require.Equal(t, c.expectedContentType, w.Header().Get("Content-Type"))
+			require.Equal(t, c.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestBuilder_WithoutResponseEncoders(t *testing.T) {
+	handler := geh.New().BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return nil, geh.NewInternalServerError(errors.New("secret"))
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/html")
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.Equal(t, `{"error":"internal server error"}`, w.Body.String())
+}
+
+func TestXMLEncoder_EncodingFailure(t *testing.T) {
+	builder := geh.New().WithResponseEncoder("application/xml", geh.XMLEncoder{})
+	handler := builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		// encoding/xml does not support maps
+		return map[string]int{"dune": 10}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/xml")
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	require.Equal(t, `<response><error>internal server error</error></response>`, w.Body.String())
+}
+
+type unencodableDetailsError struct{}
+
+func (unencodableDetailsError) Error() string     { return "bad request" }
+func (unencodableDetailsError) ErrorDetails() any { return map[string]int{"a": 1} }
+
+func TestXMLEncoder_ErrorDetailsFailure(t *testing.T) {
+	builder := geh.New().WithResponseEncoder("application/xml", geh.XMLEncoder{})
+	handler := builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return nil, geh.NewError(http.StatusBadRequest, unencodableDetailsError{})
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/xml")
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, `<response><error>internal server error</error></response>`, w.Body.String())
+}
+
+func TestXMLEncoder_ParseErrorsDetails(t *testing.T) {
+	builder := geh.New().
+		WithResponseEncoder("application/xml", geh.XMLEncoder{}).
+		WithCollectErrors()
+	geh.QueryParamInt("limit").Attach(builder)
+	geh.QueryParamInt("offset").Attach(builder)
+	handler := builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return nil, nil
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?limit=abc", nil)
+	r.Header.Set("Accept", "application/xml")
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `<response><error>limit: invalid int value: abc; offset: required query param is missing: offset</error>`+
+		`<details><param><location>query</location><name>limit</name><error>invalid int value: abc</error></param>`+
+		`<param><location>query</location><name>offset</name><error>required query param is missing: offset</error></param></details></response>`,
+		w.Body.String())
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/?limit=abc", nil)
+	r.Header.Set("Accept", "application/json")
+	handler.ServeHTTP(w, r)
+	require.Equal(t, `{"error":"limit: invalid int value: abc; offset: required query param is missing: offset",`+
+		`"details":[{"location":"query","name":"limit","error":"invalid int value: abc"},`+
+		`{"location":"query","name":"offset","error":"required query param is missing: offset"}]}`,
+		w.Body.String())
+}
@@ -0,0 +1,181 @@
+package goergohandler
+
+import (
+	"context"
+	"strings"
+)
+
+// enumValues is the set of the allowed values of an enum param.
+type enumValues[T ~string] struct {
+	values          []T
+	caseInsensitive bool
+}
+
+// parser returns a parser mapping the string to one of the values.
+// The error is ParamValueError listing the allowed values.
+func (e *enumValues[T]) parser(name string) func(ctx context.Context, v string) (T, error) {
+	return func(ctx context.Context, v string) (T, error) {
+		for _, allowed := range e.values {
+			if string(allowed) == v || (e.caseInsensitive && strings.EqualFold(string(allowed), v)) {
+				return allowed, nil
+			}
+		}
+		return "", &ParamValueError{Name: name, Type: "enum", Value: v, Allowed: e.strings()}
+	}
+}
+
+func (e *enumValues[T]) strings() []string {
+	s := make([]string, len(e.values))
+	for i, v := range e.values {
+		s[i] = string(v)
+	}
+	return s
+}
+
+// withEnum adds the allowed values to the descriptions.
+func withEnum(params []ParamDescription, enum []string) []ParamDescription {
+	for i := range params {
+		params[i].Enum = enum
+	}
+	return params
+}
+
+type QueryParamEnumType[T ~string] struct {
+	Name       string
+	ErrMissing error
+	enum       enumValues[T]
+}
+
+// QueryParamEnum is a parser for the required query param with the closed set of values:
+//
+//	type Status string
+//
+//	const (
+//		StatusDraft     Status = "draft"
+//		StatusPublished Status = "published"
+//	)
+//
+//	status := geh.QueryParamEnum("status", StatusDraft, StatusPublished).Attach(builder)
+//
+// Other values are rejected with 400 status code and the error listing the allowed values.
+// The values are reported in the param description and the OpenAPI schema.
+// Types generated with cmd/geh-enum implement WithParser and can be used with QueryParamWithParser instead.
+func QueryParamEnum[T ~string](name string, values ...T) *QueryParamEnumType[T] {
+	return &QueryParamEnumType[T]{Name: name, enum: enumValues[T]{values: values}}
+}
+
+// CaseInsensitive makes the parser accept the values in any case. The parsed value is the allowed one.
+func (qp *QueryParamEnumType[T]) CaseInsensitive() *QueryParamEnumType[T] {
+	qp.enum.caseInsensitive = true
+	return qp
+}
+
+// WithMissingError sets the error to be returned if the query param is missing.
+func (qp *QueryParamEnumType[T]) WithMissingError(err error) *QueryParamEnumType[T] {
+	qp.ErrMissing = err
+	return qp
+}
+
+func (qp *QueryParamEnumType[T]) Attach(b ParserAdder) *AttachedQueryParamEnum[T] {
+	a := &AttachedQueryParamEnum[T]{
+		AttachedQueryParam: &AttachedQueryParam[T]{
+			QueryParam(qp.Name, qp.enum.parser(qp.Name)).WithMissingError(qp.ErrMissing),
+		},
+		enum: qp.enum.strings(),
+	}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedQueryParamEnum[T ~string] struct {
+	*AttachedQueryParam[T]
+	enum []string
+}
+
+func (p *AttachedQueryParamEnum[T]) Describe() []ParamDescription {
+	return withEnum(p.AttachedQueryParam.Describe(), p.enum)
+}
+
+type QueryParamEnumMaybeType[T ~string] struct {
+	Name string
+	enum enumValues[T]
+}
+
+// QueryParamEnumMaybe is same as QueryParamEnum but it doesn't return an error if the query param is missing.
+func QueryParamEnumMaybe[T ~string](name string, values ...T) *QueryParamEnumMaybeType[T] {
+	return &QueryParamEnumMaybeType[T]{Name: name, enum: enumValues[T]{values: values}}
+}
+
+// CaseInsensitive makes the parser accept the values in any case. The parsed value is the allowed one.
+func (qp *QueryParamEnumMaybeType[T]) CaseInsensitive() *QueryParamEnumMaybeType[T] {
+	qp.enum.caseInsensitive = true
+	return qp
+}
+
+func (qp *QueryParamEnumMaybeType[T]) Attach(b ParserAdder) *AttachedQueryParamEnumMaybe[T] {
+	a := &AttachedQueryParamEnumMaybe[T]{
+		AttachedQueryParamMaybe: &AttachedQueryParamMaybe[T]{QueryParamMaybe(qp.Name, qp.enum.parser(qp.Name))},
+		enum:                    qp.enum.strings(),
+	}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedQueryParamEnumMaybe[T ~string] struct {
+	*AttachedQueryParamMaybe[T]
+	enum []string
+}
+
+func (p *AttachedQueryParamEnumMaybe[T]) Describe() []ParamDescription {
+	return withEnum(p.AttachedQueryParamMaybe.Describe(), p.enum)
+}
+
+type RouterParamEnumType[T ~string] struct {
+	Name       string
+	ErrMissing error
+	VarsGetter VarsGetter
+	enum       enumValues[T]
+}
+
+// RouterParamEnum is a parser for the router param with the closed set of values. See QueryParamEnum.
+func RouterParamEnum[T ~string](name string, values ...T) *RouterParamEnumType[T] {
+	return &RouterParamEnumType[T]{Name: name, enum: enumValues[T]{values: values}}
+}
+
+// CaseInsensitive makes the parser accept the values in any case. The parsed value is the allowed one.
+func (rp *RouterParamEnumType[T]) CaseInsensitive() *RouterParamEnumType[T] {
+	rp.enum.caseInsensitive = true
+	return rp
+}
+
+// WithMissingError sets the error to be returned if the router param is missing.
+func (rp *RouterParamEnumType[T]) WithMissingError(err error) *RouterParamEnumType[T] {
+	rp.ErrMissing = err
+	return rp
+}
+
+// WithVarsGetter sets the VarsGetter overriding the one of the builder.
+func (rp *RouterParamEnumType[T]) WithVarsGetter(varsGetter VarsGetter) *RouterParamEnumType[T] {
+	rp.VarsGetter = varsGetter
+	return rp
+}
+
+// Attach attaches the parser to the builder. The VarsGetter is resolved at this moment.
+func (rp *RouterParamEnumType[T]) Attach(builder ParserAdder) *AttachedRouterParamEnum[T] {
+	param := RouterParam(rp.Name, rp.enum.parser(rp.Name)).WithMissingError(rp.ErrMissing)
+	a := &AttachedRouterParamEnum[T]{
+		AttachedRouterParam: &AttachedRouterParam[T]{param, resolveVarsGetter(rp.VarsGetter, builder)},
+		enum:                rp.enum.strings(),
+	}
+	builder.AddParser(a)
+	return a
+}
+
+type AttachedRouterParamEnum[T ~string] struct {
+	*AttachedRouterParam[T]
+	enum []string
+}
+
+func (p *AttachedRouterParamEnum[T]) Describe() []ParamDescription {
+	return withEnum(p.AttachedRouterParam.Describe(), p.enum)
+}
@@ -0,0 +1,62 @@
+package goergohandler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/nktknshn/go-ergo-handler/adapters/stdlib"
+	"github.com/stretchr/testify/require"
+)
+
+type bookStatus string
+
+const (
+	bookStatusDraft     bookStatus = "draft"
+	bookStatusPublished bookStatus = "published"
+	bookStatusArchived  bookStatus = "archived"
+)
+
+type bookFormat string
+
+func TestEnumParams(t *testing.T) {
+	builder := geh.New().WithVarsGetter(stdlib.New())
+	format := geh.RouterParamEnum[bookFormat]("format", "pdf", "epub").Attach(builder)
+	status := geh.QueryParamEnum("status", bookStatusDraft, bookStatusPublished, bookStatusArchived).CaseInsensitive().Attach(builder)
+	sort := geh.QueryParamEnumMaybe("sort", "title", "price").Attach(builder)
+
+	mux := http.NewServeMux()
+	mux.Handle("/books/{format}", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s", format.Get(r), status.Get(r), sort.GetDefault(r, "id"))
+	}))
+
+	testCases := []struct {
+		path   string
+		status int
+		resp   string
+	}{
+		{"/books/pdf?status=draft", http.StatusOK, "pdf draft id"},
+		{"/books/epub?status=PUBLISHED&sort=price", http.StatusOK, "epub published price"},
+		{"/books/pdf", http.StatusBadRequest, `{"error":"required query param is missing: status"}`},
+		{"/books/pdf?status=deleted", http.StatusBadRequest, `{"error":"status: invalid enum value: deleted, allowed: draft, published, archived"}`},
+		{"/books/pdf?status=draft&sort=Title", http.StatusBadRequest, `{"error":"sort: invalid enum value: Title, allowed: title, price"}`},
+		{"/books/PDF?status=draft", http.StatusBadRequest, `{"error":"format: invalid enum value: PDF, allowed: pdf, epub"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+
+	var enums [][]string
+	for _, p := range builder.Describe() {
+		enums = append(enums, p.Enum)
+	}
+	require.Equal(t, [][]string{{"pdf", "epub"}, {"draft", "published", "archived"}, {"title", "price"}}, enums)
+}
@@ -35,6 +35,11 @@ type ErrorWithHeaderWriter interface {
 	WriteHeader(w http.ResponseWriter)
 }
 
+// ErrorWithDetails is an error that provides additional data to be rendered into the error response.
+type ErrorWithDetails interface {
+	ErrorDetails() any
+}
+
 // ErrorWithHttpStatus is an error that has an HTTP status code.
 type ErrorWithHttpStatus struct {
 	HttpStatusCode int
@@ -76,6 +81,9 @@ func WrapWithStatusCode(err error, code int) error {
 	return NewError(code, err)
 }
 
+// internalServerErrorMessage is the message the client gets instead of the hidden error.
+const internalServerErrorMessage = "internal server error"
+
 // InternalServerError hides the original error from the client.
 type InternalServerError struct {
 	msg string
@@ -115,7 +123,7 @@ func NewInternalServerError(err error) error {
 	if IsWrappedError(err) {
 		return err
 	}
-	return InternalServerError{Err: err, msg: "internal server error"}
+	return InternalServerError{Err: err, msg: internalServerErrorMessage}
 }
 
 // NewInternalServerErrorExpose creates an error that exposes the original error to the client.
@@ -0,0 +1,475 @@
+package goergohandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultHttpStatusCodeErrFilterParam = http.StatusBadRequest
+
+	// maxFilterDepth limits the nesting of the parentheses and NOT in the filter expression.
+	maxFilterDepth = 32
+)
+
+var (
+	ErrFilterSyntax          = errors.New("invalid filter")
+	ErrFilterUnknownField    = errors.New("unknown filter field")
+	ErrFilterUnknownOperator = errors.New("unknown filter operator")
+	// Returned when the operator or the value does not match the kind of the field.
+	ErrFilterInvalidValue = errors.New("invalid filter value")
+)
+
+// FilterOp is a comparison operator of the filter condition.
+type FilterOp string
+
+const (
+	FilterOpEq       FilterOp = "="
+	FilterOpNe       FilterOp = "!="
+	FilterOpGt       FilterOp = ">"
+	FilterOpGte      FilterOp = ">="
+	FilterOpLt       FilterOp = "<"
+	FilterOpLte      FilterOp = "<="
+	FilterOpContains FilterOp = "~"
+)
+
+var filterOps = []FilterOp{FilterOpEq, FilterOpNe, FilterOpGt, FilterOpGte, FilterOpLt, FilterOpLte, FilterOpContains}
+
+// FilterKind is the kind of the filtered field. It defines the type of FilterCondition.Value
+// and the operators allowed by default.
+type FilterKind int
+
+const (
+	// string value, all the operators
+	FilterString FilterKind = iota
+	// float64 value, all the operators except ~
+	FilterNumber
+	// bool value, = and !=
+	FilterBool
+)
+
+func (k FilterKind) String() string {
+	switch k {
+	case FilterString:
+		return "string"
+	case FilterNumber:
+		return "number"
+	case FilterBool:
+		return "bool"
+	}
+	return "unknown"
+}
+
+func (k FilterKind) defaultOps() []FilterOp {
+	switch k {
+	case FilterNumber:
+		return []FilterOp{FilterOpEq, FilterOpNe, FilterOpGt, FilterOpGte, FilterOpLt, FilterOpLte}
+	case FilterBool:
+		return []FilterOp{FilterOpEq, FilterOpNe}
+	}
+	return filterOps
+}
+
+// FilterField is a field allowed in the filter. Empty Ops means the default operators of the kind.
+type FilterField struct {
+	Name string
+	Kind FilterKind
+	Ops  []FilterOp
+}
+
+// FilterExpr is a node of the filter AST: FilterAnd, FilterOr, FilterNot or FilterCondition.
+type FilterExpr interface {
+	filterExpr()
+	String() string
+}
+
+type FilterAnd struct {
+	Left, Right FilterExpr
+}
+
+type FilterOr struct {
+	Left, Right FilterExpr
+}
+
+type FilterNot struct {
+	Expr FilterExpr
+}
+
+// FilterCondition compares the field with the value. Value is a string, float64 or bool
+// according to the kind of the field.
+type FilterCondition struct {
+	Field string
+	Op    FilterOp
+	Value any
+}
+
+func (FilterAnd) filterExpr()       {}
+func (FilterOr) filterExpr()        {}
+func (FilterNot) filterExpr()       {}
+func (FilterCondition) filterExpr() {}
+
+func (e FilterAnd) String() string { return "(" + e.Left.String() + " AND " + e.Right.String() + ")" }
+func (e FilterOr) String() string  { return "(" + e.Left.String() + " OR " + e.Right.String() + ")" }
+func (e FilterNot) String() string { return "NOT " + e.Expr.String() }
+
+func (e FilterCondition) String() string {
+	value := fmt.Sprint(e.Value)
+	if s, ok := e.Value.(string); ok {
+		value = strconv.Quote(s)
+	}
+	return e.Field + string(e.Op) + value
+}
+
+type FilterParamType struct {
+	name   string
+	fields []FilterField
+}
+
+// FilterParam is a parser for the filter expression: ?filter=price>10 AND title~"go".
+//   - conditions are field, operator and value: price>=10, title="Go in Action", published=true
+//   - operators are =, !=, >, >=, <, <= and ~ (contains)
+//   - string values are double quoted, numbers and true/false are not
+//   - conditions are combined with AND, OR, NOT and parentheses, AND binds tighter than OR
+//   - parentheses and NOT are nested at most 32 levels deep
+//
+// Only the fields from the whitelist are accepted. Unknown fields, operators not allowed for the field and
+// values not matching its kind are rejected with 400 status code and the message naming them.
+// The result is nil if the param is missing.
+func FilterParam(name string, fields ...FilterField) *FilterParamType {
+	return &FilterParamType{name: name, fields: fields}
+}
+
+func (f *FilterParamType) Attach(b ParserAdder) *AttachedFilterParam {
+	a := &AttachedFilterParam{f}
+	b.AddParser(a)
+	return a
+}
+
+// Parse parses the filter expression.
+func (f *FilterParamType) Parse(s string) (FilterExpr, error) {
+	p := &filterParser{param: f, input: s}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != filterTokenEOF {
+		return nil, p.syntaxError(t, "unexpected "+t.String())
+	}
+	return expr, nil
+}
+
+type AttachedFilterParam struct {
+	f *FilterParamType
+}
+
+func (p *AttachedFilterParam) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	var expr FilterExpr
+	if value := r.URL.Query().Get(p.f.name); strings.TrimSpace(value) != "" {
+		var err error
+		expr, err = p.f.Parse(value)
+		if err != nil {
+			return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrFilterParam)
+		}
+	}
+	return context.WithValue(ctx, p, filterExprValue{expr}), nil
+}
+
+// filterExprValue keeps the nil expression distinguishable from the missing context value.
+type filterExprValue struct {
+	expr FilterExpr
+}
+
+func (p *AttachedFilterParam) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location:      ParamLocationQuery,
+		Name:          p.f.name,
+		Type:          typeOf[string](),
+		ErrorStatuses: uniqueStatuses(defaultHttpStatusCodeErrFilterParam),
+	}}
+}
+
+// Get returns the parsed filter, nil if the param is missing.
+func (p *AttachedFilterParam) Get(r *http.Request) FilterExpr {
+	return p.GetContext(r.Context())
+}
+
+func (p *AttachedFilterParam) GetContext(ctx context.Context) FilterExpr {
+	return GetFromContext[filterExprValue](ctx, p).expr
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenEOF filterTokenKind = iota
+	filterTokenIdent
+	filterTokenString
+	filterTokenNumber
+	filterTokenOp
+	filterTokenLParen
+	filterTokenRParen
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+func (t filterToken) String() string {
+	if t.kind == filterTokenEOF {
+		return "end of filter"
+	}
+	return strconv.Quote(t.text)
+}
+
+// isKeyword reports whether the token is the keyword: AND, OR, NOT, true or false. Case insensitive.
+func (t filterToken) isKeyword(kw string) bool {
+	return t.kind == filterTokenIdent && strings.EqualFold(t.text, kw)
+}
+
+type filterParser struct {
+	param  *FilterParamType
+	input  string
+	tokens []filterToken
+	i      int
+	depth  int
+}
+
+func (p *filterParser) syntaxError(t filterToken, msg string) error {
+	return fmt.Errorf("%w: %s: %s at position %d", ErrFilterSyntax, p.param.name, msg, t.pos+1)
+}
+
+// enter goes one level deeper into the expression, the caller must call leave when it is parsed.
+func (p *filterParser) enter(t filterToken) error {
+	p.depth++
+	if p.depth > maxFilterDepth {
+		return p.syntaxError(t, fmt.Sprintf("expression is nested deeper than %d levels", maxFilterDepth))
+	}
+	return nil
+}
+
+func (p *filterParser) leave() {
+	p.depth--
+}
+
+func isFilterIdentRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || (!first && (r == '.' || unicode.IsDigit(r)))
+}
+
+func (p *filterParser) tokenize() error {
+	s := p.input
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			kind := filterTokenLParen
+			if c == ')' {
+				kind = filterTokenRParen
+			}
+			p.tokens = append(p.tokens, filterToken{kind, string(c), i})
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return p.syntaxError(filterToken{pos: i}, "unterminated string")
+			}
+			str, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return p.syntaxError(filterToken{pos: i}, "invalid string")
+			}
+			p.tokens = append(p.tokens, filterToken{filterTokenString, str, i})
+			i = j + 1
+		case strings.IndexByte("=!<>~", c) >= 0:
+			j := i
+			for j < len(s) && strings.IndexByte("=!<>~", s[j]) >= 0 {
+				j++
+			}
+			p.tokens = append(p.tokens, filterToken{filterTokenOp, s[i:j], i})
+			i = j
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || (s[j] >= '0' && s[j] <= '9')) {
+				j++
+			}
+			p.tokens = append(p.tokens, filterToken{filterTokenNumber, s[i:j], i})
+			i = j
+		default:
+			j := i
+			for j < len(s) {
+				r, size := utf8.DecodeRuneInString(s[j:])
+				if !isFilterIdentRune(r, j == i) {
+					break
+				}
+				j += size
+			}
+			if j == i {
+				r, _ := utf8.DecodeRuneInString(s[i:])
+				return p.syntaxError(filterToken{pos: i}, fmt.Sprintf("unexpected character %q", r))
+			}
+			p.tokens = append(p.tokens, filterToken{filterTokenIdent, s[i:j], i})
+			i = j
+		}
+	}
+	p.tokens = append(p.tokens, filterToken{filterTokenEOF, "", len(s)})
+	return nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.i]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.tokens[p.i]
+	if t.kind != filterTokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *filterParser) parseOr() (FilterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = FilterOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (FilterExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = FilterAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (FilterExpr, error) {
+	if t := p.peek(); t.isKeyword("NOT") {
+		p.next()
+		if err := p.enter(t); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return FilterNot{expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (FilterExpr, error) {
+	t := p.next()
+	if t.kind == filterTokenLParen {
+		if err := p.enter(t); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != filterTokenRParen {
+			return nil, p.syntaxError(closing, "expected \")\", got "+closing.String())
+		}
+		return expr, nil
+	}
+	if t.kind != filterTokenIdent || t.isKeyword("AND") || t.isKeyword("OR") {
+		return nil, p.syntaxError(t, "expected field, got "+t.String())
+	}
+	return p.parseCondition(t)
+}
+
+func (p *filterParser) parseCondition(fieldToken filterToken) (FilterExpr, error) {
+	idx := slices.IndexFunc(p.param.fields, func(f FilterField) bool { return f.Name == fieldToken.text })
+	if idx < 0 {
+		names := make([]string, len(p.param.fields))
+		for i, f := range p.param.fields {
+			names[i] = f.Name
+		}
+		return nil, fmt.Errorf("%w: %s: %s, allowed: %s", ErrFilterUnknownField, p.param.name, fieldToken.text, strings.Join(names, ", "))
+	}
+	field := p.param.fields[idx]
+
+	opToken := p.next()
+	if opToken.kind != filterTokenOp {
+		return nil, p.syntaxError(opToken, "expected operator, got "+opToken.String())
+	}
+	op := FilterOp(opToken.text)
+	if !slices.Contains(filterOps, op) {
+		return nil, fmt.Errorf("%w: %s: %s", ErrFilterUnknownOperator, p.param.name, op)
+	}
+	ops := field.Ops
+	if len(ops) == 0 {
+		ops = field.Kind.defaultOps()
+	}
+	if !slices.Contains(ops, op) {
+		return nil, fmt.Errorf("%w: %s: %s is not allowed for %s", ErrFilterUnknownOperator, p.param.name, op, field.Name)
+	}
+
+	valueToken := p.next()
+	invalid := func() error {
+		return fmt.Errorf("%w: %s: %s expects %s, got %s", ErrFilterInvalidValue, p.param.name, field.Name, field.Kind, valueToken)
+	}
+	var value any
+	switch field.Kind {
+	case FilterString:
+		if valueToken.kind != filterTokenString {
+			return nil, invalid()
+		}
+		value = valueToken.text
+	case FilterNumber:
+		if valueToken.kind != filterTokenNumber {
+			return nil, invalid()
+		}
+		n, err := strconv.ParseFloat(valueToken.text, 64)
+		if err != nil {
+			return nil, invalid()
+		}
+		value = n
+	case FilterBool:
+		switch {
+		case valueToken.isKeyword("true"):
+			value = true
+		case valueToken.isKeyword("false"):
+			value = false
+		default:
+			return nil, invalid()
+		}
+	}
+	return FilterCondition{Field: field.Name, Op: op, Value: value}, nil
+}
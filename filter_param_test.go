@@ -0,0 +1,82 @@
+package goergohandler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterParam(t *testing.T) {
+	builder := geh.New()
+	filter := geh.FilterParam("filter",
+		geh.FilterField{Name: "title", Kind: geh.FilterString},
+		geh.FilterField{Name: "price", Kind: geh.FilterNumber},
+		geh.FilterField{Name: "published", Kind: geh.FilterBool},
+		geh.FilterField{Name: "author.name", Kind: geh.FilterString, Ops: []geh.FilterOp{geh.FilterOpEq}},
+	).Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%v", filter.Get(r))
+	})
+
+	testCases := []struct {
+		filter string
+		status int
+		resp   string
+	}{
+		{"", http.StatusOK, "<nil>"},
+		{`price>10 AND title~"go"`, http.StatusOK, `(price>10 AND title~"go")`},
+		{`price>=1.5 or price<0 and published=true`, http.StatusOK, `(price>=1.5 OR (price<0 AND published=true))`},
+		{`NOT (title="a \"b\"" OR author.name="Rob") AND price!=-3`, http.StatusOK, `(NOT (title="a \"b\"" OR author.name="Rob") AND price!=-3)`},
+		{`author="Rob"`, http.StatusBadRequest, `{"error":"unknown filter field: filter: author, allowed: title, price, published, author.name"}`},
+		{`price==10`, http.StatusBadRequest, `{"error":"unknown filter operator: filter: =="}`},
+		{`price~10`, http.StatusBadRequest, `{"error":"unknown filter operator: filter: ~ is not allowed for price"}`},
+		{`author.name~"R"`, http.StatusBadRequest, `{"error":"unknown filter operator: filter: ~ is not allowed for author.name"}`},
+		{`price>"10"`, http.StatusBadRequest, `{"error":"invalid filter value: filter: price expects number, got \"10\""}`},
+		{`published=yes`, http.StatusBadRequest, `{"error":"invalid filter value: filter: published expects bool, got \"yes\""}`},
+		{`(price>10`, http.StatusBadRequest, `{"error":"invalid filter: filter: expected \")\", got end of filter at position 10"}`},
+		{`title="go`, http.StatusBadRequest, `{"error":"invalid filter: filter: unterminated string at position 7"}`},
+		{`price>10 title="go"`, http.StatusBadRequest, `{"error":"invalid filter: filter: unexpected \"title\" at position 10"}`},
+		{`price>10 AND`, http.StatusBadRequest, `{"error":"invalid filter: filter: expected field, got end of filter at position 13"}`},
+		{`price>10 # price<20`, http.StatusBadRequest, `{"error":"invalid filter: filter: unexpected character '#' at position 10"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?filter="+url.QueryEscape(tc.filter), nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+
+	expr, err := geh.FilterParam("filter", geh.FilterField{Name: "price", Kind: geh.FilterNumber}).Parse("price<=20")
+	require.NoError(t, err)
+	require.Equal(t, geh.FilterCondition{Field: "price", Op: geh.FilterOpLte, Value: 20.0}, expr)
+}
+
+func TestFilterParam_Depth(t *testing.T) {
+	f := geh.FilterParam("filter", geh.FilterField{Name: "price", Kind: geh.FilterNumber})
+
+	_, err := f.Parse(strings.Repeat("(", 32) + "price>1" + strings.Repeat(")", 32))
+	require.NoError(t, err)
+	_, err = f.Parse(strings.Repeat("NOT ", 16) + strings.Repeat("(", 16) + "price>1" + strings.Repeat(")", 16))
+	require.NoError(t, err)
+
+	_, err = f.Parse(strings.Repeat("(", 33) + "price>1" + strings.Repeat(")", 33))
+	require.ErrorIs(t, err, geh.ErrFilterSyntax)
+	require.EqualError(t, err, "invalid filter: filter: expression is nested deeper than 32 levels at position 33")
+
+	builder := geh.New()
+	f.Attach(builder)
+	w := httptest.NewRecorder()
+	builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {}).
+		ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?filter="+url.QueryEscape(strings.Repeat("NOT ", 10000)+"price>1"), nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
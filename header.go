@@ -0,0 +1,276 @@
+package goergohandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+const (
+	defaultHttpStatusCodeErrHeaderMissing    = http.StatusBadRequest
+	defaultHttpStatusCodeErrHeaderParsing    = http.StatusBadRequest
+	defaultHttpStatusCodeErrHeaderValidation = http.StatusBadRequest
+	defaultHttpStatusCodeErrHeaderRepeated   = http.StatusBadRequest
+)
+
+var (
+	ErrHeaderMissing = errors.New("required header is missing")
+	// Returned when the header is sent more than once and the parser uses HeaderRepeatReject.
+	ErrHeaderRepeated = errors.New("header must not be repeated")
+)
+
+// HeaderRepeatMode tells the header parsers what to do with the header sent more than once.
+type HeaderRepeatMode int
+
+const (
+	// HeaderRepeatFirst uses the first value.
+	HeaderRepeatFirst HeaderRepeatMode = iota
+	// HeaderRepeatLast uses the last value.
+	HeaderRepeatLast
+	// HeaderRepeatJoin joins the values with ", " as RFC 9110 allows for the list based headers like Accept or If-Match.
+	HeaderRepeatJoin
+	// HeaderRepeatReject fails with ErrHeaderRepeated.
+	HeaderRepeatReject
+)
+
+// headerValues returns the values of the header. The name is matched case-insensitively.
+func headerValues(h http.Header, name string) []string {
+	if values, ok := h[textproto.CanonicalMIMEHeaderKey(name)]; ok {
+		return values
+	}
+	// the keys set directly into the map are not canonicalized
+	for k, values := range h {
+		if strings.EqualFold(k, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// headerValue returns the value of the header combining the repeated values according to the mode.
+func headerValue(r *http.Request, name string, mode HeaderRepeatMode) (string, bool, error) {
+	values := headerValues(r.Header, name)
+	if len(values) == 0 {
+		return "", false, nil
+	}
+	switch mode {
+	case HeaderRepeatLast:
+		return values[len(values)-1], true, nil
+	case HeaderRepeatJoin:
+		return strings.Join(values, ", "), true, nil
+	case HeaderRepeatReject:
+		if len(values) > 1 {
+			return "", true, WrapWithStatusCode(fmt.Errorf("%w: %s", ErrHeaderRepeated, name), defaultHttpStatusCodeErrHeaderRepeated)
+		}
+	}
+	return values[0], true, nil
+}
+
+func newHeaderMissingError(name string) error {
+	return fmt.Errorf("%w: %s", ErrHeaderMissing, name)
+}
+
+type HeaderParserFunc[T any] func(ctx context.Context, v string) (T, error)
+
+type HeaderType[T any] struct {
+	Name       string
+	Parser     HeaderParserFunc[T]
+	ErrMissing error
+	repeat     HeaderRepeatMode
+}
+
+// Header is a parser that parses a required header from the request. The name is matched case-insensitively.
+// If the header is missing, it returns ErrHeaderMissing.
+// If the type implements WithValidation, it will be validated.
+// Example:
+//
+//	tenantID := geh.Header("X-Tenant-ID", geh.IgnoreContext(uuid.Parse)).Attach(builder)
+func Header[T any](name string, parser HeaderParserFunc[T]) *HeaderType[T] {
+	return &HeaderType[T]{
+		Name:   name,
+		Parser: parser,
+	}
+}
+
+// WithMissingError sets the error to be returned if the header is missing.
+func (h *HeaderType[T]) WithMissingError(err error) *HeaderType[T] {
+	h.ErrMissing = err
+	return h
+}
+
+// WithRepeated sets how the header sent more than once is handled. Default is HeaderRepeatFirst.
+func (h *HeaderType[T]) WithRepeated(mode HeaderRepeatMode) *HeaderType[T] {
+	h.repeat = mode
+	return h
+}
+
+func (h *HeaderType[T]) Attach(b ParserAdder) *AttachedHeader[T] {
+	a := &AttachedHeader[T]{h}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedHeader[T any] struct {
+	h *HeaderType[T]
+}
+
+func (p *AttachedHeader[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	vstr, ok, err := headerValue(r, p.h.Name, p.h.repeat)
+	if err != nil {
+		return ctx, err
+	}
+	if !ok {
+		err := p.h.ErrMissing
+		if err == nil {
+			err = newHeaderMissingError(p.h.Name)
+		}
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrHeaderMissing)
+	}
+	v, err := p.h.Parser(ctx, vstr)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrHeaderParsing)
+	}
+	err = ValidateWithValidation(v)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrHeaderValidation)
+	}
+	return context.WithValue(ctx, p, v), nil
+}
+
+func (p *AttachedHeader[T]) Describe() []ParamDescription {
+	return []ParamDescription{describeHeader[T](p.h.Name, true, p.h.repeat)}
+}
+
+func describeHeader[T any](name string, required bool, repeat HeaderRepeatMode) ParamDescription {
+	statuses := []int{defaultHttpStatusCodeErrHeaderParsing, defaultHttpStatusCodeErrHeaderValidation}
+	if required {
+		statuses = append(statuses, defaultHttpStatusCodeErrHeaderMissing)
+	}
+	if repeat == HeaderRepeatReject {
+		statuses = append(statuses, defaultHttpStatusCodeErrHeaderRepeated)
+	}
+	return ParamDescription{
+		Location:      ParamLocationHeader,
+		Name:          name,
+		Type:          typeOf[T](),
+		Required:      required,
+		ErrorStatuses: uniqueStatuses(statuses...),
+	}
+}
+
+func (p *AttachedHeader[T]) Get(r *http.Request) T {
+	return p.GetContext(r.Context())
+}
+
+func (p *AttachedHeader[T]) GetContext(ctx context.Context) T {
+	return GetFromContext[T](ctx, p)
+}
+
+type HeaderMaybeType[T any] struct {
+	Name   string
+	Parser HeaderParserFunc[T]
+	repeat HeaderRepeatMode
+}
+
+// HeaderMaybe is same as Header but it doesn't return an error if the header is missing.
+func HeaderMaybe[T any](name string, parser HeaderParserFunc[T]) *HeaderMaybeType[T] {
+	return &HeaderMaybeType[T]{
+		Name:   name,
+		Parser: parser,
+	}
+}
+
+// WithRepeated sets how the header sent more than once is handled. Default is HeaderRepeatFirst.
+func (h *HeaderMaybeType[T]) WithRepeated(mode HeaderRepeatMode) *HeaderMaybeType[T] {
+	h.repeat = mode
+	return h
+}
+
+func (h *HeaderMaybeType[T]) Attach(b ParserAdder) *AttachedHeaderMaybe[T] {
+	a := &AttachedHeaderMaybe[T]{h}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedHeaderMaybe[T any] struct {
+	h *HeaderMaybeType[T]
+}
+
+func (p *AttachedHeaderMaybe[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	vstr, ok, err := headerValue(r, p.h.Name, p.h.repeat)
+	if err != nil {
+		return ctx, err
+	}
+	if !ok {
+		return ctx, nil
+	}
+	v, err := p.h.Parser(ctx, vstr)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrHeaderParsing)
+	}
+	err = ValidateWithValidation(v)
+	if err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrHeaderValidation)
+	}
+	return context.WithValue(ctx, p, v), nil
+}
+
+func (p *AttachedHeaderMaybe[T]) Describe() []ParamDescription {
+	return []ParamDescription{describeHeader[T](p.h.Name, false, p.h.repeat)}
+}
+
+func (p *AttachedHeaderMaybe[T]) GetMaybe(r *http.Request) (*T, bool) {
+	return p.GetContextMaybe(r.Context())
+}
+
+func (p *AttachedHeaderMaybe[T]) GetDefault(r *http.Request, defaultVal T) T {
+	return p.GetContextDefault(r.Context(), defaultVal)
+}
+
+func (p *AttachedHeaderMaybe[T]) GetContextDefault(ctx context.Context, defaultVal T) T {
+	v, ok := p.GetContextMaybe(ctx)
+	if !ok {
+		return defaultVal
+	}
+	return *v
+}
+
+func (p *AttachedHeaderMaybe[T]) GetContextMaybe(ctx context.Context) (*T, bool) {
+	return GetFromContextMaybe[T](ctx, p)
+}
+
+type HeaderWithParserType[T WithParser[T]] struct {
+	Name       string
+	ErrMissing error
+	repeat     HeaderRepeatMode
+}
+
+// HeaderWithParser is same as Header but it uses the Parse method of the type.
+func HeaderWithParser[T WithParser[T]](name string) *HeaderWithParserType[T] {
+	return &HeaderWithParserType[T]{
+		Name: name,
+	}
+}
+
+// WithMissingError sets the error to be returned if the header is missing.
+func (h *HeaderWithParserType[T]) WithMissingError(err error) *HeaderWithParserType[T] {
+	h.ErrMissing = err
+	return h
+}
+
+// WithRepeated sets how the header sent more than once is handled. Default is HeaderRepeatFirst.
+func (h *HeaderWithParserType[T]) WithRepeated(mode HeaderRepeatMode) *HeaderWithParserType[T] {
+	h.repeat = mode
+	return h
+}
+
+func (h *HeaderWithParserType[T]) Attach(b ParserAdder) *AttachedHeader[T] {
+	var instance T
+	return Header(h.Name, instance.Parse).
+		WithMissingError(h.ErrMissing).
+		WithRepeated(h.repeat).
+		Attach(b)
+}
@@ -0,0 +1,118 @@
+package goergohandler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type etag string
+
+func (etag) Parse(ctx context.Context, v string) (etag, error) {
+	if !strings.HasPrefix(v, `"`) || !strings.HasSuffix(v, `"`) {
+		return "", errors.New("invalid etag")
+	}
+	return etag(v), nil
+}
+
+func TestHeader(t *testing.T) {
+	builder := geh.New()
+	tenant := geh.Header("x-tenant-id", geh.IgnoreContext(strconv.Atoi)).Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strconv.Itoa(tenant.Get(r))))
+	})
+
+	testCases := []struct {
+		name   string
+		header http.Header
+		status int
+		resp   string
+	}{
+		{"canonical", http.Header{"X-Tenant-Id": {"42"}}, http.StatusOK, "42"},
+		{"not canonical", http.Header{"x-tenant-id": {"7"}}, http.StatusOK, "7"},
+		{"repeated", http.Header{"X-Tenant-Id": {"1", "2"}}, http.StatusOK, "1"},
+		{"missing", http.Header{}, http.StatusBadRequest, `{"error":"required header is missing: x-tenant-id"}`},
+		{"invalid", http.Header{"X-Tenant-Id": {"abc"}}, http.StatusBadRequest, `{"error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header = tc.header
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+}
+
+func TestHeader_Repeated(t *testing.T) {
+	identity := geh.IgnoreContext(func(s string) (string, error) { return s, nil })
+	values := []string{`"a"`, `"b"`}
+
+	testCases := []struct {
+		mode   geh.HeaderRepeatMode
+		status int
+		resp   string
+	}{
+		{geh.HeaderRepeatFirst, http.StatusOK, `"a"`},
+		{geh.HeaderRepeatLast, http.StatusOK, `"b"`},
+		{geh.HeaderRepeatJoin, http.StatusOK, `"a", "b"`},
+		{geh.HeaderRepeatReject, http.StatusBadRequest, `{"error":"header must not be repeated: If-Match"}`},
+	}
+
+	for _, tc := range testCases {
+		builder := geh.New()
+		ifMatch := geh.Header("If-Match", identity).WithRepeated(tc.mode).Attach(builder)
+		handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(ifMatch.Get(r)))
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header["If-Match"] = values
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, tc.status, w.Code)
+		require.Equal(t, tc.resp, w.Body.String())
+	}
+}
+
+func TestHeaderMaybe(t *testing.T) {
+	builder := geh.New()
+	requestID := geh.HeaderMaybe("X-Request-Id", geh.IgnoreContext(strconv.Atoi)).Attach(builder)
+	ifMatch := geh.HeaderWithParser[etag]("If-Match").Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strconv.Itoa(requestID.GetDefault(r, -1)) + " " + string(ifMatch.Get(r))))
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-Match", `"v1"`)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, `-1 "v1"`, w.Body.String())
+
+	r.Header.Set("X-Request-Id", "10")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, `10 "v1"`, w.Body.String())
+
+	r.Header.Set("If-Match", "v1")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"invalid etag"}`, w.Body.String())
+
+	params := builder.Describe()
+	require.Equal(t, "header X-Request-Id int optional", params[0].String())
+	require.Equal(t, "header If-Match goergohandler_test.etag required", params[1].String())
+}
@@ -0,0 +1,375 @@
+package goergohandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The documents are decoded with UseNumber into map[string]any, []any, json.Number, string, bool and nil.
+
+// decodeJSONDocument decodes the json document keeping the numbers as json.Number.
+func decodeJSONDocument(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ErrPayloadTrailingData
+	}
+	return doc, nil
+}
+
+// applyMergePatch applies the RFC 7396 merge patch to the target.
+func applyMergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = applyMergePatch(t[k], v)
+	}
+	return t
+}
+
+// jsonPatchOperation is an operation of the RFC 6902 JSON Patch.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// parseJSONPatch parses the RFC 6902 JSON Patch document checking the required members of the operations.
+func parseJSONPatch(data []byte) ([]jsonPatchOperation, error) {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		if op.Path == nil {
+			return nil, fmt.Errorf("operation %d: missing path", i)
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("operation %d: missing value", i)
+			}
+		case "move", "copy":
+			if op.From == nil {
+				return nil, fmt.Errorf("operation %d: missing from", i)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d: unknown op %q", i, op.Op)
+		}
+	}
+	return ops, nil
+}
+
+// jsonPatchError is returned when an operation cannot be applied to the document.
+type jsonPatchError struct {
+	index int
+	op    string
+	msg   string
+}
+
+func (e *jsonPatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s): %s", e.index, e.op, e.msg)
+}
+
+// applyJSONPatch applies the operations to the document. The document is modified in place.
+func applyJSONPatch(doc any, ops []jsonPatchOperation) (any, error) {
+	for i, op := range ops {
+		var err error
+		doc, err = applyJSONPatchOperation(doc, op)
+		if err != nil {
+			return nil, &jsonPatchError{index: i, op: op.Op, msg: err.Error()}
+		}
+	}
+	return doc, nil
+}
+
+func applyJSONPatchOperation(doc any, op jsonPatchOperation) (any, error) {
+	path, err := parseJSONPointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := decodeJSONDocument(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		switch op.Op {
+		case "add":
+			return jsonPatchAdd(doc, path, value)
+		case "replace":
+			if _, err := jsonPointerGet(doc, path); err != nil {
+				return nil, err
+			}
+			doc, _, err = jsonPatchRemove(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			return jsonPatchAdd(doc, path, value)
+		default:
+			actual, err := jsonPointerGet(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonEqual(actual, value) {
+				return nil, fmt.Errorf("test failed at %s", *op.Path)
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err = jsonPatchRemove(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := parseJSONPointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			value, err := jsonPointerGet(doc, from)
+			if err != nil {
+				return nil, err
+			}
+			return jsonPatchAdd(doc, path, copyJSONDocument(value))
+		}
+		if *op.Path != *op.From && strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into its child %s", *op.From, *op.Path)
+		}
+		doc, value, err := jsonPatchRemove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(doc, path, value)
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parseJSONPointer splits the RFC 6901 JSON Pointer into the unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func jsonPointerGet(doc any, path []string) (any, error) {
+	for i, token := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			v, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %s", formatJSONPointer(path[:i+1]))
+			}
+			doc = v
+		case []any:
+			idx, err := jsonArrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", formatJSONPointer(path[:i+1]), err)
+			}
+			doc = c[idx]
+		default:
+			return nil, fmt.Errorf("path not found: %s", formatJSONPointer(path[:i+1]))
+		}
+	}
+	return doc, nil
+}
+
+// jsonUpdateParent calls f with the container referenced by all but the last token of the path
+// and replaces the container with the result. The container must exist.
+func jsonUpdateParent(doc any, path []string, f func(parent any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return f(doc, path[0])
+	}
+	switch c := doc.(type) {
+	case map[string]any:
+		child, ok := c[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("path not found: /%s", path[0])
+		}
+		updated, err := jsonUpdateParent(child, path[1:], f)
+		if err != nil {
+			return nil, err
+		}
+		c[path[0]] = updated
+		return c, nil
+	case []any:
+		idx, err := jsonArrayIndex(path[0], len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := jsonUpdateParent(c[idx], path[1:], f)
+		if err != nil {
+			return nil, err
+		}
+		c[idx] = updated
+		return c, nil
+	}
+	return nil, fmt.Errorf("path not found: /%s", path[0])
+}
+
+func jsonPatchAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	if _, err := jsonPointerGet(doc, path[:len(path)-1]); err != nil {
+		return nil, err
+	}
+	return jsonUpdateParent(doc, path, func(parent any, key string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			c[key] = value
+			return c, nil
+		case []any:
+			if key == "-" {
+				return append(c, value), nil
+			}
+			idx, err := jsonArrayIndex(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:idx], append([]any{value}, c[idx:]...)...), nil
+		}
+		return nil, fmt.Errorf("cannot add %s to a scalar", formatJSONPointer(path))
+	})
+}
+
+// jsonPatchRemove removes the value at the path and returns the document and the removed value.
+func jsonPatchRemove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	if _, err := jsonPointerGet(doc, path[:len(path)-1]); err != nil {
+		return nil, nil, err
+	}
+	var removed any
+	doc, err := jsonUpdateParent(doc, path, func(parent any, key string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			v, ok := c[key]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %s", formatJSONPointer(path))
+			}
+			removed = v
+			delete(c, key)
+			return c, nil
+		case []any:
+			idx, err := jsonArrayIndex(key, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[idx]
+			return append(c[:idx], c[idx+1:]...), nil
+		}
+		return nil, fmt.Errorf("path not found: %s", formatJSONPointer(path))
+	})
+	return doc, removed, err
+}
+
+// jsonArrayIndex parses the array index token allowing values up to max.
+func jsonArrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if idx > max {
+		return 0, fmt.Errorf("array index %d out of range", idx)
+	}
+	return idx, nil
+}
+
+func formatJSONPointer(path []string) string {
+	var sb strings.Builder
+	for _, t := range path {
+		sb.WriteByte('/')
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
+
+func copyJSONDocument(doc any) any {
+	switch c := doc.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(c))
+		for k, v := range c {
+			m[k] = copyJSONDocument(v)
+		}
+		return m
+	case []any:
+		s := make([]any, len(c))
+		for i, v := range c {
+			s[i] = copyJSONDocument(v)
+		}
+		return s
+	}
+	return doc
+}
+
+// jsonEqual compares the json documents, numbers are compared by value.
+func jsonEqual(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		if aerr != nil || berr != nil {
+			return an == bn
+		}
+		return af == bf
+	}
+	switch ac := a.(type) {
+	case map[string]any:
+		bc, ok := b.(map[string]any)
+		if !ok || len(ac) != len(bc) {
+			return false
+		}
+		for k, v := range ac {
+			bv, ok := bc[k]
+			if !ok || !jsonEqual(v, bv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bc, ok := b.([]any)
+		if !ok || len(ac) != len(bc) {
+			return false
+		}
+		for i := range ac {
+			if !jsonEqual(ac[i], bc[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,393 @@
+package goergohandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	defaultHttpStatusCodeErrMultipartParsing     = http.StatusBadRequest
+	defaultHttpStatusCodeErrMultipartValidation  = http.StatusBadRequest
+	defaultHttpStatusCodeErrMultipartTooLarge    = http.StatusRequestEntityTooLarge
+	defaultHttpStatusCodeErrMultipartUnsupported = http.StatusUnsupportedMediaType
+	defaultHttpStatusCodeErrFileUploadMissing    = http.StatusBadRequest
+
+	mediaTypeMultipartForm = "multipart/form-data"
+
+	// DefaultMultipartMaxMemory is the number of bytes of the files kept in memory.
+	// The rest is spooled to temporary files removed when the request is done.
+	DefaultMultipartMaxMemory int64 = 32 << 20
+)
+
+var (
+	ErrMultipartParsing = errors.New("error parsing multipart form")
+	// Returned when the whole body or a single file exceeds the limit.
+	ErrMultipartTooLarge = errors.New("multipart form is too large")
+	// Returned when the Content-Type of a file is not allowed.
+	ErrFileTypeNotAllowed = errors.New("file type is not allowed")
+	ErrFileUploadMissing  = errors.New("file is missing")
+)
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+// multipartOptions are the limits shared by the multipart parsers.
+type multipartOptions struct {
+	maxMemory    int64
+	maxTotalSize int64
+	maxFileSize  int64
+	allowedTypes []string
+}
+
+// parseMultipart parses the multipart form of the request checking the limits.
+// Only the files with the names are checked against the file limits.
+// The temporary files of the form are removed when the request is done.
+// If the form was already parsed by another parser, the total size of its values and files is checked
+// against the limit and the form is reused along with the memory limit of the first parser.
+func parseMultipart(w http.ResponseWriter, r *http.Request, opts multipartOptions, names []string) (*multipart.Form, error) {
+	if r.MultipartForm == nil {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != mediaTypeMultipartForm {
+			return nil, WrapWithStatusCode(ErrPayloadUnsupportedMediaType, defaultHttpStatusCodeErrMultipartUnsupported)
+		}
+		if opts.maxTotalSize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, opts.maxTotalSize)
+		}
+		maxMemory := opts.maxMemory
+		if maxMemory <= 0 {
+			maxMemory = DefaultMultipartMaxMemory
+		}
+		err = r.ParseMultipartForm(maxMemory)
+		if r.MultipartForm != nil {
+			form := r.MultipartForm
+			RegisterCleanup(r.Context(), func() { _ = form.RemoveAll() })
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return nil, WrapWithStatusCode(ErrMultipartTooLarge, defaultHttpStatusCodeErrMultipartTooLarge)
+			}
+			return nil, WrapWithStatusCode(ErrMultipartParsing, defaultHttpStatusCodeErrMultipartParsing)
+		}
+	} else if opts.maxTotalSize > 0 && formSize(r.MultipartForm) > opts.maxTotalSize {
+		return nil, WrapWithStatusCode(ErrMultipartTooLarge, defaultHttpStatusCodeErrMultipartTooLarge)
+	}
+	form := r.MultipartForm
+	for _, name := range names {
+		for _, fh := range form.File[name] {
+			if opts.maxFileSize > 0 && fh.Size > opts.maxFileSize {
+				return nil, WrapWithStatusCode(
+					fmt.Errorf("%w: %s exceeds %d bytes", ErrMultipartTooLarge, name, opts.maxFileSize),
+					defaultHttpStatusCodeErrMultipartTooLarge,
+				)
+			}
+			if !opts.typeAllowed(fh) {
+				return nil, WrapWithStatusCode(
+					fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, name),
+					defaultHttpStatusCodeErrMultipartUnsupported,
+				)
+			}
+		}
+	}
+	return form, nil
+}
+
+// formSize returns the size of the values and the files of the parsed form without the multipart framing.
+func formSize(form *multipart.Form) int64 {
+	var n int64
+	for _, values := range form.Value {
+		for _, v := range values {
+			n += int64(len(v))
+		}
+	}
+	for _, files := range form.File {
+		for _, fh := range files {
+			n += fh.Size
+		}
+	}
+	return n
+}
+
+// typeAllowed checks the Content-Type of the file against the allowed types.
+// Types like image/* match any subtype.
+func (o multipartOptions) typeAllowed(fh *multipart.FileHeader) bool {
+	if len(o.allowedTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(fh.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, allowed := range o.allowedTypes {
+		allowed = strings.ToLower(allowed)
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if allowed == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+func (o multipartOptions) errorStatuses() []int {
+	return uniqueStatuses(
+		defaultHttpStatusCodeErrMultipartParsing,
+		defaultHttpStatusCodeErrMultipartValidation,
+		defaultHttpStatusCodeErrMultipartTooLarge,
+		defaultHttpStatusCodeErrMultipartUnsupported,
+	)
+}
+
+// decodeFiles sets the *multipart.FileHeader and []*multipart.FileHeader fields of the struct
+// pointed by v from the files of the form. The fields are matched by the form tag.
+func decodeFiles(files map[string][]*multipart.FileHeader, v any) {
+	rv := reflect.ValueOf(v).Elem()
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fhs := files[name]
+		if len(fhs) == 0 {
+			continue
+		}
+		switch f.Type {
+		case fileHeaderType:
+			rv.Field(i).Set(reflect.ValueOf(fhs[0]))
+		case reflect.SliceOf(fileHeaderType):
+			rv.Field(i).Set(reflect.ValueOf(fhs))
+		}
+	}
+}
+
+// isFileField reports whether the struct field holds uploaded files.
+func isFileField(f reflect.StructField) bool {
+	return f.Type == fileHeaderType || f.Type == reflect.SliceOf(fileHeaderType)
+}
+
+type MultipartPayloadType[T any] struct {
+	opts multipartOptions
+}
+
+// MultipartPayload is a parser that parses the multipart/form-data body into the struct T.
+// The fields are matched by the form tag: `form:"title"`. Fields of type *multipart.FileHeader
+// or []*multipart.FileHeader get the uploaded files, other fields are parsed like the FormDecoder does.
+// Files bigger than the memory limit are spooled to temporary files which are removed when the request is done.
+// If T implements WithValidation, it will be validated.
+func MultipartPayload[T any]() *MultipartPayloadType[T] {
+	return &MultipartPayloadType[T]{}
+}
+
+// WithMaxMemory sets the number of bytes of the files kept in memory. Default is DefaultMultipartMaxMemory.
+func (p *MultipartPayloadType[T]) WithMaxMemory(n int64) *MultipartPayloadType[T] {
+	p.opts.maxMemory = n
+	return p
+}
+
+// WithMaxTotalSize limits the size of the whole body. Bigger requests are rejected with 413 status code.
+func (p *MultipartPayloadType[T]) WithMaxTotalSize(n int64) *MultipartPayloadType[T] {
+	p.opts.maxTotalSize = n
+	return p
+}
+
+// WithMaxFileSize limits the size of every file of the parser. Bigger files are rejected with 413 status code.
+func (p *MultipartPayloadType[T]) WithMaxFileSize(n int64) *MultipartPayloadType[T] {
+	p.opts.maxFileSize = n
+	return p
+}
+
+// WithAllowedTypes sets the allowed Content-Types of the files of the parser, e.g. "image/png" or "image/*".
+// Other files are rejected with 415 status code.
+func (p *MultipartPayloadType[T]) WithAllowedTypes(mediaTypes ...string) *MultipartPayloadType[T] {
+	p.opts.allowedTypes = mediaTypes
+	return p
+}
+
+func (p *MultipartPayloadType[T]) Attach(builder ParserAdder) *AttachedMultipartPayload[T] {
+	a := &AttachedMultipartPayload[T]{p}
+	builder.AddParser(a)
+	return a
+}
+
+type AttachedMultipartPayload[T any] struct {
+	p *MultipartPayloadType[T]
+}
+
+func (p *AttachedMultipartPayload[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	form, err := parseMultipart(w, r, p.p.opts, fileFieldNames[T]())
+	if err != nil {
+		return ctx, err
+	}
+	var v T
+	if err := decodeValues(ctx, formValuesWithoutFiles[T](form), &v, "form"); err != nil {
+		return ctx, WrapWithStatusCode(fmt.Errorf("%w: %w", ErrMultipartParsing, err), defaultHttpStatusCodeErrMultipartParsing)
+	}
+	decodeFiles(form.File, &v)
+	if err := ValidateWithValidation(v); err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrMultipartValidation)
+	}
+	return context.WithValue(ctx, p, v), nil
+}
+
+// formValuesWithoutFiles returns the values of the form without the names of the file fields of T,
+// so the file fields are not parsed from the text values.
+func formValuesWithoutFiles[T any](form *multipart.Form) map[string][]string {
+	names := fileFieldNames[T]()
+	if len(names) == 0 {
+		return form.Value
+	}
+	values := maps.Clone(form.Value)
+	for _, name := range names {
+		delete(values, name)
+	}
+	return values
+}
+
+// fileFieldNames returns the form names of the file fields of T.
+func fileFieldNames[T any]() []string {
+	t := typeOf[T]()
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || !isFileField(f) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func (p *AttachedMultipartPayload[T]) readsMultipart() {}
+
+func (p *AttachedMultipartPayload[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location:      ParamLocationBody,
+		Type:          typeOf[T](),
+		Required:      true,
+		MediaTypes:    []string{mediaTypeMultipartForm},
+		ErrorStatuses: p.p.opts.errorStatuses(),
+	}}
+}
+
+func (p *AttachedMultipartPayload[T]) Get(r *http.Request) T {
+	return p.GetContext(r.Context())
+}
+
+func (p *AttachedMultipartPayload[T]) GetContext(ctx context.Context) T {
+	return GetFromContext[T](ctx, p)
+}
+
+type FileUploadType struct {
+	name string
+	opts multipartOptions
+}
+
+// FileUpload is a parser that requires the file with the name in the multipart/form-data body.
+// The limits are the same as for MultipartPayload. Missing file is rejected with 400 status code.
+func FileUpload(name string) *FileUploadType {
+	return &FileUploadType{name: name}
+}
+
+// WithMaxMemory sets the number of bytes of the files kept in memory. Default is DefaultMultipartMaxMemory.
+func (p *FileUploadType) WithMaxMemory(n int64) *FileUploadType {
+	p.opts.maxMemory = n
+	return p
+}
+
+// WithMaxTotalSize limits the size of the whole body. Bigger requests are rejected with 413 status code.
+func (p *FileUploadType) WithMaxTotalSize(n int64) *FileUploadType {
+	p.opts.maxTotalSize = n
+	return p
+}
+
+// WithMaxFileSize limits the size of every file of the parser. Bigger files are rejected with 413 status code.
+func (p *FileUploadType) WithMaxFileSize(n int64) *FileUploadType {
+	p.opts.maxFileSize = n
+	return p
+}
+
+// WithAllowedTypes sets the allowed Content-Types of the files of the parser, e.g. "image/png" or "image/*".
+// Other files are rejected with 415 status code.
+func (p *FileUploadType) WithAllowedTypes(mediaTypes ...string) *FileUploadType {
+	p.opts.allowedTypes = mediaTypes
+	return p
+}
+
+func (p *FileUploadType) Attach(builder ParserAdder) *AttachedFileUpload {
+	a := &AttachedFileUpload{p}
+	builder.AddParser(a)
+	return a
+}
+
+type AttachedFileUpload struct {
+	p *FileUploadType
+}
+
+func (p *AttachedFileUpload) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	form, err := parseMultipart(w, r, p.p.opts, []string{p.p.name})
+	if err != nil {
+		return ctx, err
+	}
+	files := form.File[p.p.name]
+	if len(files) == 0 {
+		return ctx, WrapWithStatusCode(fmt.Errorf("%w: %s", ErrFileUploadMissing, p.p.name), defaultHttpStatusCodeErrFileUploadMissing)
+	}
+	return context.WithValue(ctx, p, files), nil
+}
+
+func (p *AttachedFileUpload) readsMultipart() {}
+
+func (p *AttachedFileUpload) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location:      ParamLocationBody,
+		Name:          p.p.name,
+		Type:          fileHeaderType,
+		Required:      true,
+		MediaTypes:    []string{mediaTypeMultipartForm},
+		ErrorStatuses: uniqueStatuses(append(p.p.opts.errorStatuses(), defaultHttpStatusCodeErrFileUploadMissing)...),
+	}}
+}
+
+// Get returns the first file with the name.
+func (p *AttachedFileUpload) Get(r *http.Request) *multipart.FileHeader {
+	return p.GetContext(r.Context())
+}
+
+func (p *AttachedFileUpload) GetContext(ctx context.Context) *multipart.FileHeader {
+	return p.GetAllContext(ctx)[0]
+}
+
+// GetAll returns all the files with the name.
+func (p *AttachedFileUpload) GetAll(r *http.Request) []*multipart.FileHeader {
+	return p.GetAllContext(r.Context())
+}
+
+func (p *AttachedFileUpload) GetAllContext(ctx context.Context) []*multipart.FileHeader {
+	return GetFromContext[[]*multipart.FileHeader](ctx, p)
+}
@@ -0,0 +1,260 @@
+package goergohandler_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type uploadPayload struct {
+	Title  string                  `form:"title"`
+	Pages  int                     `form:"pages"`
+	Cover  *multipart.FileHeader   `form:"cover"`
+	Extras []*multipart.FileHeader `form:"extra"`
+}
+
+func (p uploadPayload) Validate() error {
+	if p.Title == "" {
+		return errors.New("title is required")
+	}
+	return nil
+}
+
+type multipartFile struct {
+	field, name, contentType, content string
+}
+
+func newMultipartRequest(t *testing.T, fields map[string]string, files ...multipartFile) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		require.NoError(t, mw.WriteField(k, v))
+	}
+	for _, f := range files {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+f.field+`"; filename="`+f.name+`"`)
+		h.Set("Content-Type", f.contentType)
+		part, err := mw.CreatePart(h)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func readFileHeader(t *testing.T, fh *multipart.FileHeader) string {
+	t.Helper()
+	f, err := fh.Open()
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestMultipartPayload(t *testing.T) {
+	builder := geh.New()
+	payload := geh.MultipartPayload[uploadPayload]().Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		p := payload.Get(r)
+		require.Equal(t, "book", p.Title)
+		require.Equal(t, 42, p.Pages)
+		require.Equal(t, "cover.png", p.Cover.Filename)
+		require.Equal(t, "png-data", readFileHeader(t, p.Cover))
+		require.Len(t, p.Extras, 2)
+		require.Equal(t, "b", readFileHeader(t, p.Extras[1]))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t,
+		map[string]string{"title": "book", "pages": "42"},
+		multipartFile{"cover", "cover.png", "image/png", "png-data"},
+		multipartFile{"extra", "a.txt", "text/plain", "a"},
+		multipartFile{"extra", "b.txt", "text/plain", "b"},
+	))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, map[string]string{"pages": "1"}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"title is required"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, map[string]string{"title": "book", "pages": "many"}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title":"book"}`)))
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestMultipartPayload_Limits(t *testing.T) {
+	builder := geh.New()
+	geh.MultipartPayload[uploadPayload]().
+		WithMaxTotalSize(1024).
+		WithMaxFileSize(8).
+		WithAllowedTypes("image/*").
+		Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := []struct {
+		name   string
+		file   multipartFile
+		status int
+	}{
+		{"ok", multipartFile{"cover", "c.png", "image/png", "small"}, http.StatusOK},
+		{"file too large", multipartFile{"cover", "c.png", "image/png", "more than eight"}, http.StatusRequestEntityTooLarge},
+		{"body too large", multipartFile{"cover", "c.png", "image/png", strings.Repeat("x", 2048)}, http.StatusRequestEntityTooLarge},
+		{"type not allowed", multipartFile{"cover", "c.txt", "text/plain", "small"}, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newMultipartRequest(t, map[string]string{"title": "book"}, tc.file))
+			require.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestFileUpload(t *testing.T) {
+	builder := geh.New()
+	file := geh.FileUpload("document").WithMaxMemory(1).Attach(builder)
+
+	var spooled string
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		fh := file.Get(r)
+		require.Equal(t, "doc.pdf", fh.Filename)
+		require.Equal(t, "%PDF-1.7 content", readFileHeader(t, fh))
+		// the file does not fit into the memory so it is spooled to a temporary file
+		f, err := fh.Open()
+		require.NoError(t, err)
+		spooled = f.(*os.File).Name()
+		f.Close()
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, nil, multipartFile{"document", "doc.pdf", "application/pdf", "%PDF-1.7 content"}))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEmpty(t, spooled)
+	_, err := os.Stat(spooled)
+	require.True(t, errors.Is(err, os.ErrNotExist), "temporary file %s is not removed", filepath.Base(spooled))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, map[string]string{"title": "book"}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"file is missing: document"}`, w.Body.String())
+}
+
+func TestFileUpload_LimitsOfOtherFiles(t *testing.T) {
+	builder := geh.New()
+	avatar := geh.FileUpload("avatar").WithMaxFileSize(8).WithAllowedTypes("image/*").Attach(builder)
+	document := geh.FileUpload("document").Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(avatar.Get(r).Filename + " " + document.Get(r).Filename))
+	})
+
+	// the limits of avatar are not applied to document
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, nil,
+		multipartFile{"avatar", "a.png", "image/png", "small"},
+		multipartFile{"document", "doc.pdf", "application/pdf", "%PDF-1.7 content"},
+	))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "a.png doc.pdf", w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, nil,
+		multipartFile{"avatar", "a.txt", "text/plain", "small"},
+		multipartFile{"document", "doc.pdf", "application/pdf", "%PDF-1.7 content"},
+	))
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestMultipartPayload_LimitsOfOtherFiles(t *testing.T) {
+	builder := geh.New()
+	geh.MultipartPayload[uploadPayload]().WithMaxFileSize(8).Attach(builder)
+	document := geh.FileUpload("document").Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(document.Get(r).Filename))
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, map[string]string{"title": "book"},
+		multipartFile{"cover", "c.png", "image/png", "small"},
+		multipartFile{"document", "doc.pdf", "application/pdf", "%PDF-1.7 content"},
+	))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "doc.pdf", w.Body.String())
+}
+
+func TestMultipart_TotalSizeOfParsedForm(t *testing.T) {
+	builder := geh.New()
+	geh.FileUpload("document").Attach(builder)
+	geh.MultipartPayload[uploadPayload]().WithMaxTotalSize(16).Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, map[string]string{"title": "book"},
+		multipartFile{"document", "doc.pdf", "application/pdf", "%PDF-1.7 content"},
+	))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newMultipartRequest(t, map[string]string{"title": "book"},
+		multipartFile{"document", "doc.pdf", "application/pdf", "%PDF"},
+	))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMultipart_ConcurrentGroup(t *testing.T) {
+	builder := geh.New()
+	group := builder.Concurrent()
+	limit := geh.QueryParamInt("limit").Attach(group)
+	payload := geh.MultipartPayload[uploadPayload]().WithMaxMemory(16).Attach(group)
+	document := geh.FileUpload("document").Attach(group)
+
+	r := newMultipartRequest(t, map[string]string{"title": "book"},
+		multipartFile{"document", "doc.pdf", "application/pdf", "%PDF-1.7 " + strings.Repeat("x", 1024)},
+	)
+	r.URL.RawQuery = "limit=10"
+	original := r.Body
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		// the unlimited multipart parsers read the original body instead of a copy in memory
+		require.True(t, r.Body == original)
+		require.Equal(t, 10, limit.Get(r))
+		require.Equal(t, "book", payload.Get(r).Title)
+
+		f, err := document.Get(r).Open()
+		require.NoError(t, err)
+		defer f.Close()
+		_, spooled := f.(*os.File)
+		require.True(t, spooled)
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,290 @@
+// Package openapi generates an OpenAPI 3.1 document from the handlers built with the goergohandler builder.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"gopkg.in/yaml.v3"
+)
+
+const Version = "3.1.0"
+
+const errorSchemaName = "Error"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi" yaml:"openapi"`
+	Info       Info                 `json:"info" yaml:"info"`
+	Paths      map[string]*PathItem `json:"paths" yaml:"paths"`
+	Components Components           `json:"components" yaml:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// PathItem maps lowercase HTTP methods to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses" yaml:"responses"`
+	Security    []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name" yaml:"name"`
+	In       string  `json:"in" yaml:"in"`
+	Required bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *Schema `json:"schema" yaml:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content" yaml:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema" yaml:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description" yaml:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type" yaml:"type"`
+	Scheme       string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty" yaml:"in,omitempty"`
+	Name         string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// Spec collects the handlers and builds the document.
+type Spec struct {
+	doc     *Document
+	schemas *schemaGenerator
+}
+
+// New creates an empty spec with the given info.
+func New(info Info) *Spec {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				errorSchemaName: {
+					Type:       "object",
+					Properties: map[string]*Schema{"error": {Type: "string"}, "details": {}},
+					Required:   []string{"error"},
+				},
+			},
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+	}
+	return &Spec{doc: doc, schemas: newSchemaGenerator(doc.Components.Schemas)}
+}
+
+// Add registers a handler built by the builder under the method and path.
+// The path uses the OpenAPI template syntax: /books/{book_id}.
+// Parameters, request body, security and error responses are taken from the parsers attached to the builder.
+// Handlers that were not built by the builder are added with the default responses only.
+// The returned operation can be used to set the summary, tags etc.
+func (s *Spec) Add(method, path string, h http.Handler) *Operation {
+	var params []geh.ParamDescription
+	if d, ok := h.(geh.DescribedParser); ok {
+		params = d.Describe()
+	}
+	op := s.operation(params)
+
+	item, ok := s.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		s.doc.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+	return op
+}
+
+func (s *Spec) operation(params []geh.ParamDescription) *Operation {
+	op := &Operation{Responses: map[string]*Response{}}
+
+	statuses := map[int]bool{http.StatusInternalServerError: true}
+
+	for _, p := range params {
+		for _, status := range p.ErrorStatuses {
+			statuses[status] = true
+		}
+		switch p.Location {
+		case geh.ParamLocationQuery, geh.ParamLocationPath, geh.ParamLocationHeader, geh.ParamLocationCookie:
+			schema := s.schemas.schemaFor(p.Type)
+			if len(p.Enum) > 0 {
+				// the values of the param replace the ones of the type
+				schema.Enum = make([]any, len(p.Enum))
+				for i, v := range p.Enum {
+					schema.Enum[i] = v
+				}
+			}
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:     p.Name,
+				In:       string(p.Location),
+				Required: p.Required || p.Location == geh.ParamLocationPath,
+				Schema:   schema,
+			})
+		case geh.ParamLocationBody:
+			mediaTypes := p.MediaTypes
+			if len(mediaTypes) == 0 {
+				mediaTypes = []string{"application/json"}
+			}
+			if p.Name != "" {
+				// a named part of the multipart form, e.g. a file upload
+				s.addBodyPart(op, p, mediaTypes)
+				continue
+			}
+			schema := s.schemas.schemaFor(p.Type)
+			op.RequestBody = &RequestBody{Required: p.Required, Content: map[string]*MediaType{}}
+			for _, mt := range mediaTypes {
+				if isFormMediaType(mt) {
+					// the form fields are named by the form tag
+					op.RequestBody.Content[mt] = &MediaType{Schema: s.schemas.formSchema(p.Type)}
+					continue
+				}
+				op.RequestBody.Content[mt] = &MediaType{Schema: schema}
+			}
+		case geh.ParamLocationAuth:
+			s.doc.Components.SecuritySchemes[p.Name] = securityScheme(p.AuthScheme)
+			if !p.Required {
+				// an empty requirement makes the security optional
+				op.Security = append(op.Security, map[string][]string{})
+			}
+			op.Security = append(op.Security, map[string][]string{p.Name: {}})
+		}
+	}
+
+	op.Responses[strconv.Itoa(http.StatusOK)] = &Response{
+		Description: http.StatusText(http.StatusOK),
+		Content: map[string]*MediaType{
+			"application/json": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"result": {}},
+			}},
+		},
+	}
+
+	codes := make([]int, 0, len(statuses))
+	for status := range statuses {
+		codes = append(codes, status)
+	}
+	sort.Ints(codes)
+	for _, status := range codes {
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content: map[string]*MediaType{
+				"application/json": {Schema: &Schema{Ref: "#/components/schemas/" + errorSchemaName}},
+			},
+		}
+	}
+	return op
+}
+
+// addBodyPart adds the named part to the object schema of the request body.
+func (s *Spec) addBodyPart(op *Operation, p geh.ParamDescription, mediaTypes []string) {
+	if op.RequestBody == nil {
+		op.RequestBody = &RequestBody{Content: map[string]*MediaType{}}
+	}
+	op.RequestBody.Required = op.RequestBody.Required || p.Required
+	for _, mt := range mediaTypes {
+		content, ok := op.RequestBody.Content[mt]
+		if !ok || content.Schema.Properties == nil {
+			content = &MediaType{Schema: &Schema{Type: "object", Properties: map[string]*Schema{}}}
+			op.RequestBody.Content[mt] = content
+		}
+		content.Schema.Properties[p.Name] = s.schemas.schemaFor(p.Type)
+		if p.Required {
+			content.Schema.Required = append(content.Schema.Required, p.Name)
+		}
+	}
+}
+
+// Document returns the generated document.
+func (s *Spec) Document() *Document {
+	return s.doc
+}
+
+// JSON returns the document marshalled to json.
+func (s *Spec) JSON() ([]byte, error) {
+	return json.MarshalIndent(s.doc, "", "  ")
+}
+
+// YAML returns the document marshalled to yaml.
+func (s *Spec) YAML() ([]byte, error) {
+	return yaml.Marshal(s.doc)
+}
+
+// Handler serves the document. YAML is served if the request path ends with .yaml or .yml,
+// the format query param is yaml or the Accept header asks for yaml. Otherwise JSON is served.
+func (s *Spec) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			bs          []byte
+			err         error
+			contentType string
+		)
+		if wantsYAML(r) {
+			bs, err = s.YAML()
+			contentType = "application/yaml"
+		} else {
+			bs, err = s.JSON()
+			contentType = "application/json"
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(bs)
+	})
+}
+
+func wantsYAML(r *http.Request) bool {
+	switch path.Ext(r.URL.Path) {
+	case ".yaml", ".yml":
+		return true
+	}
+	if r.URL.Query().Get("format") == "yaml" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "yaml")
+}
+
+// securityScheme converts the scheme of the auth parser, the parsers without it are described as bearer.
+func securityScheme(scheme *geh.AuthScheme) *SecurityScheme {
+	if scheme == nil {
+		scheme = &geh.AuthSchemeBearer
+	}
+	return &SecurityScheme{
+		Type:   scheme.Type,
+		Scheme: scheme.Scheme,
+		In:     string(scheme.In),
+		Name:   scheme.Name,
+	}
+}
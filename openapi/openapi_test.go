@@ -0,0 +1,224 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/nktknshn/go-ergo-handler/openapi"
+	"github.com/stretchr/testify/require"
+)
+
+type bookPayload struct {
+	Title string   `json:"title"`
+	Price int      `json:"price"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type user struct {
+	ID int
+}
+
+type userKeyType string
+
+type tokenValidator struct{}
+
+func (tokenValidator) ValidateToken(ctx context.Context, token string) (*user, bool, error) {
+	return &user{ID: 1}, true, nil
+}
+
+func makeHandler() http.Handler {
+	var (
+		builder = geh.New()
+		_       = geh.AuthParser[user](userKeyType("user"), geh.TokenBearerFromHeader).Attach(tokenValidator{}, builder)
+		_       = geh.RouterParam("book_id", geh.IgnoreContext(strconv.Atoi)).Attach(builder)
+		_       = geh.QueryParamBoolMaybe("unpublish").Attach(builder)
+		_       = geh.Payload[bookPayload]().Attach(builder)
+	)
+	return builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return nil, nil
+	})
+}
+
+func TestSpec_Add(t *testing.T) {
+	spec := openapi.New(openapi.Info{Title: "Books", Version: "1.0.0"})
+	spec.Add(http.MethodPut, "/books/{book_id}", makeHandler()).Summary = "Update a book"
+
+	doc := spec.Document()
+	require.Equal(t, openapi.Version, doc.OpenAPI)
+
+	op := (*doc.Paths["/books/{book_id}"])["put"]
+	require.NotNil(t, op)
+	require.Equal(t, "Update a book", op.Summary)
+
+	require.Len(t, op.Parameters, 2)
+	require.Equal(t, "book_id", op.Parameters[0].Name)
+	require.Equal(t, "path", op.Parameters[0].In)
+	require.True(t, op.Parameters[0].Required)
+	require.Equal(t, "integer", op.Parameters[0].Schema.Type)
+	require.Equal(t, "unpublish", op.Parameters[1].Name)
+	require.Equal(t, "query", op.Parameters[1].In)
+	require.False(t, op.Parameters[1].Required)
+	require.Equal(t, "boolean", op.Parameters[1].Schema.Type)
+
+	require.NotNil(t, op.RequestBody)
+	require.Equal(t, "#/components/schemas/bookPayload", op.RequestBody.Content["application/json"].Schema.Ref)
+	body := doc.Components.Schemas["bookPayload"]
+	require.Equal(t, "object", body.Type)
+	require.Equal(t, []string{"title", "price"}, body.Required)
+	require.Equal(t, "array", body.Properties["tags"].Type)
+
+	require.Equal(t, []map[string][]string{{geh.DefaultAuthSecurityScheme: {}}}, op.Security)
+	require.Equal(t, "bearer", doc.Components.SecuritySchemes[geh.DefaultAuthSecurityScheme].Scheme)
+
+	for _, status := range []string{"200", "400", "401", "500"} {
+		require.Contains(t, op.Responses, status)
+	}
+}
+
+func TestSpec_Handler(t *testing.T) {
+	spec := openapi.New(openapi.Info{Title: "Books", Version: "1.0.0"})
+	spec.Add(http.MethodPut, "/books/{book_id}", makeHandler())
+
+	w := httptest.NewRecorder()
+	spec.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	require.Equal(t, openapi.Version, doc["openapi"])
+
+	w = httptest.NewRecorder()
+	spec.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/openapi.yaml", nil))
+	require.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+	require.True(t, strings.HasPrefix(w.Body.String(), "openapi: 3.1.0"))
+}
+
+func TestSpec_FileUpload(t *testing.T) {
+	builder := geh.New()
+	geh.FileUpload("cover").Attach(builder)
+	geh.FileUpload("back").Attach(builder)
+
+	spec := openapi.New(openapi.Info{Title: "Books", Version: "1.0.0"})
+	op := spec.Add(http.MethodPost, "/covers", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {}))
+
+	require.True(t, op.RequestBody.Required)
+	schema := op.RequestBody.Content["multipart/form-data"].Schema
+	require.Equal(t, "object", schema.Type)
+	require.Equal(t, []string{"cover", "back"}, schema.Required)
+	require.Equal(t, &openapi.Schema{Type: "string", Format: "binary"}, schema.Properties["cover"])
+	for _, status := range []string{"400", "413", "415"} {
+		require.Contains(t, op.Responses, status)
+	}
+}
+
+func tokenFromSessionCookie(ctx context.Context, r *http.Request) (string, bool, error) {
+	c, err := r.Cookie("session")
+	if err != nil {
+		return "", false, nil
+	}
+	return c.Value, true, nil
+}
+
+func TestSpec_APIKeySecurityScheme(t *testing.T) {
+	builder := geh.New()
+	geh.AuthParser[user](userKeyType("user"), tokenFromSessionCookie).
+		WithSecurityScheme("sessionCookie").
+		WithAuthScheme(geh.AuthSchemeAPIKey(geh.ParamLocationCookie, "session")).
+		Attach(tokenValidator{}, builder)
+
+	spec := openapi.New(openapi.Info{Title: "Books", Version: "1.0.0"})
+	op := spec.Add(http.MethodGet, "/me", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {}))
+
+	require.Equal(t, []map[string][]string{{"sessionCookie": {}}}, op.Security)
+	require.Equal(t,
+		&openapi.SecurityScheme{Type: "apiKey", In: "cookie", Name: "session"},
+		spec.Document().Components.SecuritySchemes["sessionCookie"],
+	)
+}
+
+type bookFormat string
+
+func (bookFormat) EnumValues() []string {
+	return []string{"pdf", "epub"}
+}
+
+func TestSpec_Enum(t *testing.T) {
+	builder := geh.New()
+	geh.QueryParamEnum("status", "draft", "published").Attach(builder)
+	geh.QueryParam("format", func(ctx context.Context, v string) (bookFormat, error) {
+		return bookFormat(v), nil
+	}).Attach(builder)
+	geh.QueryParamEnum("type", bookFormat("pdf"), bookFormat("epub"), bookFormat("mobi")).Attach(builder)
+
+	spec := openapi.New(openapi.Info{Title: "Books", Version: "1.0.0"})
+	op := spec.Add(http.MethodGet, "/books", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {}))
+
+	require.Equal(t, &openapi.Schema{Type: "string", Enum: []any{"draft", "published"}}, op.Parameters[0].Schema)
+	require.Equal(t, &openapi.Schema{Type: "string", Enum: []any{"pdf", "epub"}}, op.Parameters[1].Schema)
+	// the values of the param replace the EnumValues of the type
+	require.Equal(t, &openapi.Schema{Type: "string", Enum: []any{"pdf", "epub", "mobi"}}, op.Parameters[2].Schema)
+}
+
+type coverForm struct {
+	Title  string                  `json:"title_json" form:"title"`
+	Pages  int                     `form:"pages"`
+	Hidden string                  `form:"-"`
+	Cover  *multipart.FileHeader   `form:"cover"`
+	Extras []*multipart.FileHeader `form:"extra"`
+}
+
+func TestSpec_FormSchema(t *testing.T) {
+	builder := geh.New()
+	geh.MultipartPayload[coverForm]().Attach(builder)
+	geh.FileUpload("back").Attach(builder)
+
+	spec := openapi.New(openapi.Info{Title: "Books", Version: "1.0.0"})
+	op := spec.Add(http.MethodPost, "/covers", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {}))
+
+	schema := op.RequestBody.Content["multipart/form-data"].Schema
+	require.Equal(t, "object", schema.Type)
+	require.Equal(t, map[string]*openapi.Schema{
+		"title": {Type: "string"},
+		"pages": {Type: "integer", Format: "int64"},
+		"cover": {Type: "string", Format: "binary"},
+		"extra": {Type: "array", Items: &openapi.Schema{Type: "string", Format: "binary"}},
+		"back":  {Type: "string", Format: "binary"},
+	}, schema.Properties)
+	require.Equal(t, []string{"back"}, schema.Required)
+
+	builder = geh.New()
+	geh.Payload[coverForm]().Attach(builder)
+	op = spec.Add(http.MethodPut, "/covers", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {}))
+
+	require.Equal(t, "#/components/schemas/coverForm", op.RequestBody.Content["application/json"].Schema.Ref)
+	form := op.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+	require.Contains(t, form.Properties, "title")
+	require.NotContains(t, form.Properties, "title_json")
+}
+
+type bookPatch struct {
+	Title  geh.Optional[string]      `json:"title"`
+	Price  geh.Optional[int]         `json:"price"`
+	Author geh.Optional[bookPayload] `json:"author"`
+	ISBN   string                    `json:"isbn"`
+}
+
+func TestSpec_Optional(t *testing.T) {
+	builder := geh.New()
+	geh.Payload[bookPatch]().Attach(builder)
+
+	spec := openapi.New(openapi.Info{Title: "Books", Version: "1.0.0"})
+	spec.Add(http.MethodPatch, "/books/{book_id}", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {}))
+
+	schema := spec.Document().Components.Schemas["bookPatch"]
+	require.Equal(t, &openapi.Schema{Type: "string", Nullable: true}, schema.Properties["title"])
+	require.Equal(t, &openapi.Schema{Type: "integer", Format: "int64", Nullable: true}, schema.Properties["price"])
+	require.Equal(t, &openapi.Schema{Ref: "#/components/schemas/bookPayload", Nullable: true}, schema.Properties["author"])
+	require.Equal(t, []string{"isbn"}, schema.Required)
+}
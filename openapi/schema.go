@@ -0,0 +1,244 @@
+package openapi
+
+import (
+	"encoding"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+)
+
+// Schema is a subset of the JSON Schema used by OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	fileHeaderType    = reflect.TypeOf(multipart.FileHeader{})
+	uuidType          = reflect.TypeOf(geh.UUID{})
+	urlType           = reflect.TypeOf(url.URL{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	enumValuesType    = reflect.TypeOf((*enumValues)(nil)).Elem()
+	optionalType      = reflect.TypeOf((*optional)(nil)).Elem()
+	schemaNameCleaner = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// enumValues is implemented by the enum types generated by cmd/geh-enum.
+type enumValues interface {
+	EnumValues() []string
+}
+
+// optional is implemented by geh.Optional, the field is described as nullable value.
+type optional interface {
+	OptionalType() reflect.Type
+}
+
+// schemaGenerator generates schemas for Go types. Named struct types are placed into
+// the components and referenced by $ref.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator(schemas map[string]*Schema) *schemaGenerator {
+	return &schemaGenerator{schemas: schemas, names: map[reflect.Type]string{}}
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Implements(optionalType) {
+		s := *g.schemaFor(reflect.Zero(t).Interface().(optional).OptionalType())
+		s.Nullable = true
+		return &s
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t == fileHeaderType {
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	if t == uuidType {
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	if t == urlType {
+		return &Schema{Type: "string", Format: "uri"}
+	}
+	if t.Implements(enumValuesType) {
+		s := &Schema{Type: "string"}
+		for _, v := range reflect.Zero(t).Interface().(enumValues).EnumValues() {
+			s.Enum = append(s.Enum, v)
+		}
+		return s
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.componentName(t)}
+	}
+	// interfaces, funcs and channels accept any value
+	return &Schema{}
+}
+
+// componentName registers the named type in the components and returns its name.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := schemaNameCleaner.ReplaceAllString(t.Name(), "_")
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = schemaNameCleaner.ReplaceAllString(pkg, "_") + "." + name
+	}
+	g.names[t] = name
+	// reserve the name before generating to support recursive types
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitempty, skip := jsonFieldName(f)
+		if skip {
+			continue
+		}
+		ft := f.Type
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		s.Properties[name] = g.schemaFor(f.Type)
+		// the optional fields can be absent
+		if !omitempty && f.Type.Kind() != reflect.Pointer && !f.Type.Implements(optionalType) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// isFormMediaType reports whether the body of the media type is decoded by the form tag.
+func isFormMediaType(mediaType string) bool {
+	return mediaType == "multipart/form-data" || mediaType == "application/x-www-form-urlencoded"
+}
+
+// formSchema returns the inline object schema of the struct decoded from a form.
+// The fields are named by the form tag and none of them is required.
+func (g *schemaGenerator) formSchema(t reflect.Type) *Schema {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return g.schemaFor(t)
+	}
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFormFields(s, t)
+	return s
+}
+
+func (g *schemaGenerator) addFormFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("form")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			g.addFormFields(s, ft)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.schemaFor(f.Type)
+	}
+}
+
+// jsonFieldName returns the name of the field as encoding/json would marshal it.
+func jsonFieldName(f reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	for _, o := range strings.Split(opts, ",") {
+		if o == "omitempty" || o == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
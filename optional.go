@@ -0,0 +1,86 @@
+package goergohandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Optional is a json field telling apart the absent field, the field set to null and the field set to a value.
+// Use it in PATCH payloads:
+//
+//	type bookPatch struct {
+//		Title       geh.Optional[string] `json:"title"`
+//		Description geh.Optional[string] `json:"description"`
+//	}
+//
+// {"title": "Dune"} sets Title, leaves Description unset; {"description": null} sets Description to null.
+type Optional[T any] struct {
+	Value T
+	// the field is present in the payload
+	Set bool
+	// the field is set to null
+	Null bool
+}
+
+// OptionalValue returns the Optional set to the value.
+func OptionalValue[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Set: true}
+}
+
+// OptionalNull returns the Optional set to null.
+func OptionalNull[T any]() Optional[T] {
+	return Optional[T]{Set: true, Null: true}
+}
+
+// Get returns the value and true if the field is set to a value.
+func (o Optional[T]) Get() (T, bool) {
+	if !o.Set || o.Null {
+		var zero T
+		return zero, false
+	}
+	return o.Value, true
+}
+
+// Apply sets dst to the value if the field is set to a value or to the zero value if it is set to null.
+// dst is left unchanged if the field is absent.
+func (o Optional[T]) Apply(dst *T) {
+	if !o.Set {
+		return
+	}
+	var zero T
+	if o.Null {
+		*dst = zero
+		return
+	}
+	*dst = o.Value
+}
+
+// OptionalType returns the type of the value. The schema generators describe the field as nullable T.
+func (Optional[T]) OptionalType() reflect.Type {
+	return typeOf[T]()
+}
+
+// IsZero reports whether the field is absent. It makes the field omitted with the omitzero json option.
+func (o Optional[T]) IsZero() bool {
+	return !o.Set
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	o.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.Value = zero
+		o.Null = true
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
@@ -0,0 +1,250 @@
+package goergohandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultHttpStatusCodeErrPagination = http.StatusBadRequest
+
+	DefaultPaginationLimit    = 20
+	DefaultPaginationMaxLimit = 100
+
+	paginationLimitParam  = "limit"
+	paginationOffsetParam = "offset"
+	paginationCursorParam = "cursor"
+)
+
+var (
+	ErrPaginationInvalidLimit  = errors.New("invalid limit")
+	ErrPaginationInvalidOffset = errors.New("invalid offset")
+	// Returned when the cursor is not signed by any of the keys.
+	ErrPaginationInvalidCursor = errors.New("invalid cursor")
+)
+
+// PageRequest is the page requested by the client.
+type PageRequest struct {
+	Limit int
+	// Offset of the first item. Always zero in the cursor mode.
+	Offset int
+	// Cursor is the value passed to NewCursorPage for the previous page. Empty for the first page
+	// and in the offset mode.
+	Cursor string
+	// nil in the offset mode
+	codec CookieCodec
+	// the name the cursors are signed with
+	cursorName string
+}
+
+type PaginationType struct {
+	codec        CookieCodec
+	cursorName   string
+	defaultLimit int
+	maxLimit     int
+}
+
+// OffsetPagination is a parser reading the page from the limit and offset query params.
+// Missing limit means DefaultPaginationLimit, the limit above DefaultPaginationMaxLimit is rejected with 400 status code.
+// Return the result of NewPage from the handler to write has_more and the Link header.
+func OffsetPagination() *PaginationType {
+	return &PaginationType{
+		defaultLimit: DefaultPaginationLimit,
+		maxLimit:     DefaultPaginationMaxLimit,
+	}
+}
+
+// CursorPagination is a parser reading the page from the limit and cursor query params.
+// The cursors are opaque to the client: they are signed with HMAC-SHA256 by the first key
+// and checked against all the keys, so the keys can be rotated by prepending the new one.
+// The name of the endpoint, e.g. "books", is signed along with the cursor as cursor:books,
+// so the cursors of one endpoint are rejected by the others sharing the keys.
+// Forged cursors are rejected with ErrPaginationInvalidCursor and 400 status code.
+// Return the result of NewCursorPage from the handler to write next_cursor, has_more and the Link header.
+func CursorPagination(name string, keys ...[]byte) *PaginationType {
+	codec, err := NewSignedCookieCodec(keys...)
+	if err != nil {
+		panic(fmt.Sprintf("CursorPagination: %v", err))
+	}
+	return &PaginationType{
+		codec:        codec,
+		cursorName:   paginationCursorParam + ":" + name,
+		defaultLimit: DefaultPaginationLimit,
+		maxLimit:     DefaultPaginationMaxLimit,
+	}
+}
+
+// WithDefaultLimit sets the limit used when the limit param is missing.
+func (p *PaginationType) WithDefaultLimit(n int) *PaginationType {
+	p.defaultLimit = n
+	return p
+}
+
+// WithMaxLimit sets the biggest page size a client can request.
+func (p *PaginationType) WithMaxLimit(n int) *PaginationType {
+	p.maxLimit = n
+	return p
+}
+
+func (p *PaginationType) Attach(b ParserAdder) *AttachedPagination {
+	a := &AttachedPagination{p}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedPagination struct {
+	p *PaginationType
+}
+
+func (a *AttachedPagination) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	query := r.URL.Query()
+	req := PageRequest{Limit: a.p.defaultLimit, codec: a.p.codec, cursorName: a.p.cursorName}
+
+	if query.Has(paginationLimitParam) {
+		limit, err := strconv.Atoi(query.Get(paginationLimitParam))
+		if err != nil || limit < 1 {
+			return ctx, WrapWithStatusCode(fmt.Errorf("%w: must be a positive integer", ErrPaginationInvalidLimit), defaultHttpStatusCodeErrPagination)
+		}
+		if limit > a.p.maxLimit {
+			return ctx, WrapWithStatusCode(fmt.Errorf("%w: must not exceed %d", ErrPaginationInvalidLimit, a.p.maxLimit), defaultHttpStatusCodeErrPagination)
+		}
+		req.Limit = limit
+	}
+
+	if a.p.codec == nil {
+		if query.Has(paginationOffsetParam) {
+			offset, err := strconv.Atoi(query.Get(paginationOffsetParam))
+			if err != nil || offset < 0 {
+				return ctx, WrapWithStatusCode(fmt.Errorf("%w: must be a non-negative integer", ErrPaginationInvalidOffset), defaultHttpStatusCodeErrPagination)
+			}
+			req.Offset = offset
+		}
+	} else if cursor := query.Get(paginationCursorParam); cursor != "" {
+		value, err := a.p.codec.Decode(a.p.cursorName, cursor)
+		if err != nil {
+			return ctx, WrapWithStatusCode(ErrPaginationInvalidCursor, defaultHttpStatusCodeErrPagination)
+		}
+		req.Cursor = value
+	}
+
+	return context.WithValue(ctx, a, req), nil
+}
+
+func (a *AttachedPagination) Describe() []ParamDescription {
+	statuses := uniqueStatuses(defaultHttpStatusCodeErrPagination)
+	params := []ParamDescription{{
+		Location:      ParamLocationQuery,
+		Name:          paginationLimitParam,
+		Type:          typeOf[int](),
+		ErrorStatuses: statuses,
+	}}
+	if a.p.codec == nil {
+		return append(params, ParamDescription{
+			Location:      ParamLocationQuery,
+			Name:          paginationOffsetParam,
+			Type:          typeOf[int](),
+			ErrorStatuses: statuses,
+		})
+	}
+	return append(params, ParamDescription{
+		Location:      ParamLocationQuery,
+		Name:          paginationCursorParam,
+		Type:          typeOf[string](),
+		ErrorStatuses: statuses,
+	})
+}
+
+func (a *AttachedPagination) Get(r *http.Request) PageRequest {
+	return a.GetContext(r.Context())
+}
+
+func (a *AttachedPagination) GetContext(ctx context.Context) PageRequest {
+	return GetFromContext[PageRequest](ctx, a)
+}
+
+// Page is a result holding a page of items. DefaultHandlerResultFunc renders it into
+// {"result": [...items], "has_more": true, "next_cursor": "..."} and sets the RFC 8288 Link header
+// with the next and prev pages.
+type Page[T any] struct {
+	Items   []T
+	HasMore bool
+	// NextCursor is the signed cursor of the next page. Empty in the offset mode and for the last page.
+	NextCursor string
+	req        PageRequest
+}
+
+// NewPage returns the page of items for the offset pagination.
+func NewPage[T any](req PageRequest, items []T, hasMore bool) Page[T] {
+	return Page[T]{Items: items, HasMore: hasMore, req: req}
+}
+
+// NewCursorPage returns the page of items for the cursor pagination. nextCursor is the value the next page
+// starts from, e.g. the id of the last item, or empty string if there are no more items.
+// It is signed and comes back as PageRequest.Cursor with the next request.
+func NewCursorPage[T any](req PageRequest, items []T, nextCursor string) (Page[T], error) {
+	page := Page[T]{Items: items, HasMore: nextCursor != "", req: req}
+	if nextCursor == "" || req.codec == nil {
+		return page, nil
+	}
+	signed, err := req.codec.Encode(req.cursorName, nextCursor)
+	if err != nil {
+		return page, err
+	}
+	page.NextCursor = signed
+	return page, nil
+}
+
+func (p Page[T]) EnvelopeResult() any {
+	if p.Items == nil {
+		return []T{}
+	}
+	return p.Items
+}
+
+func (p Page[T]) EnvelopeFields() map[string]any {
+	fields := map[string]any{"has_more": p.HasMore}
+	if p.NextCursor != "" {
+		fields["next_cursor"] = p.NextCursor
+	}
+	return fields
+}
+
+// WriteLinks sets the Link header with the pages next to the page of the request.
+func (p Page[T]) WriteLinks(w http.ResponseWriter, r *http.Request) {
+	var links []string
+	link := func(rel string, set map[string]string) {
+		u := *r.URL
+		query := u.Query()
+		for k, v := range set {
+			if v == "" {
+				query.Del(k)
+			} else {
+				query.Set(k, v)
+			}
+		}
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel))
+	}
+	limit := strconv.Itoa(p.req.Limit)
+
+	if p.req.codec != nil {
+		if p.NextCursor != "" {
+			link("next", map[string]string{paginationLimitParam: limit, paginationCursorParam: p.NextCursor})
+		}
+	} else {
+		if p.HasMore {
+			link("next", map[string]string{paginationLimitParam: limit, paginationOffsetParam: strconv.Itoa(p.req.Offset + p.req.Limit)})
+		}
+		if p.req.Offset > 0 {
+			prev := max(p.req.Offset-p.req.Limit, 0)
+			link("prev", map[string]string{paginationLimitParam: limit, paginationOffsetParam: strconv.Itoa(prev)})
+		}
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,137 @@
+package goergohandler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagination_Offset(t *testing.T) {
+	builder := geh.New()
+	pagination := geh.OffsetPagination().WithMaxLimit(10).Attach(builder)
+
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+
+	handler := builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		page := pagination.Get(r)
+		from, to := min(page.Offset, len(items)), min(page.Offset+page.Limit, len(items))
+		return geh.NewPage(page, items[from:to], to < len(items)), nil
+	})
+
+	testCases := []struct {
+		query  string
+		status int
+		resp   string
+		link   string
+	}{
+		{"", http.StatusOK, `{"has_more":false,"result":[1,2,3,4,5,6,7]}`, ""},
+		{"limit=3", http.StatusOK, `{"has_more":true,"result":[1,2,3]}`, `</books?limit=3&offset=3>; rel="next"`},
+		{"limit=3&offset=3&q=go", http.StatusOK, `{"has_more":true,"result":[4,5,6]}`,
+			`</books?limit=3&offset=6&q=go>; rel="next", </books?limit=3&offset=0&q=go>; rel="prev"`},
+		{"limit=3&offset=20", http.StatusOK, `{"has_more":false,"result":[]}`, `</books?limit=3&offset=17>; rel="prev"`},
+		{"limit=11", http.StatusBadRequest, `{"error":"invalid limit: must not exceed 10"}`, ""},
+		{"limit=0", http.StatusBadRequest, `{"error":"invalid limit: must be a positive integer"}`, ""},
+		{"offset=-1", http.StatusBadRequest, `{"error":"invalid offset: must be a non-negative integer"}`, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/books?"+tc.query, nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+			require.Equal(t, tc.link, w.Header().Get("Link"))
+		})
+	}
+
+	var names []string
+	for _, p := range builder.Describe() {
+		names = append(names, p.String())
+	}
+	require.Equal(t, []string{"query limit int optional", "query offset int optional"}, names)
+}
+
+func TestPagination_Cursor(t *testing.T) {
+	builder := geh.New()
+	pagination := geh.CursorPagination("books", []byte("new key"), []byte("old key")).WithDefaultLimit(2).Attach(builder)
+
+	items := []int{1, 2, 3, 4, 5}
+
+	handler := builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		page := pagination.Get(r)
+		from := 0
+		if page.Cursor != "" {
+			from, _ = strconv.Atoi(page.Cursor)
+		}
+		to := min(from+page.Limit, len(items))
+		next := ""
+		if to < len(items) {
+			next = strconv.Itoa(to)
+		}
+		return geh.NewCursorPage(page, items[from:to], next)
+	})
+
+	type pageResponse struct {
+		Result     []int  `json:"result"`
+		HasMore    bool   `json:"has_more"`
+		NextCursor string `json:"next_cursor"`
+	}
+
+	get := func(t *testing.T, query string) (*httptest.ResponseRecorder, pageResponse) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/books?"+query, nil))
+		var resp pageResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp
+	}
+
+	var all []int
+	query := ""
+	for {
+		w, resp := get(t, query)
+		require.Equal(t, http.StatusOK, w.Code)
+		all = append(all, resp.Result...)
+		if !resp.HasMore {
+			require.Empty(t, resp.NextCursor)
+			require.Empty(t, w.Header().Get("Link"))
+			break
+		}
+		query = "cursor=" + url.QueryEscape(resp.NextCursor)
+		require.Equal(t, fmt.Sprintf(`</books?%s&limit=2>; rel="next"`, query), w.Header().Get("Link"))
+	}
+	require.Equal(t, items, all)
+
+	// cursors signed by the old key are accepted
+	oldCursor, err := geh.EncodeCookie(mustSignedCodec(t, []byte("old key")), &http.Cookie{Name: "cursor:books", Value: "4"})
+	require.NoError(t, err)
+	w, resp := get(t, "cursor="+url.QueryEscape(oldCursor.Value))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []int{5}, resp.Result)
+
+	w, _ = get(t, "cursor=4")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"invalid cursor"}`, w.Body.String())
+
+	// cursors of another endpoint with the same keys are rejected
+	otherCursor, err := geh.EncodeCookie(mustSignedCodec(t, []byte("new key")), &http.Cookie{Name: "cursor:authors", Value: "4"})
+	require.NoError(t, err)
+	w, _ = get(t, "cursor="+url.QueryEscape(otherCursor.Value))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	require.Panics(t, func() { geh.CursorPagination("books") })
+}
+
+func mustSignedCodec(t *testing.T, keys ...[]byte) geh.CookieCodec {
+	codec, err := geh.NewSignedCookieCodec(keys...)
+	require.NoError(t, err)
+	return codec
+}
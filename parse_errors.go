@@ -0,0 +1,187 @@
+package goergohandler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ParseError is an error returned by a parser when the builder collects errors.
+// It carries the location and the name of the parameter the parser failed on.
+type ParseError struct {
+	Location ParamLocation
+	Name     string
+	Err      error
+}
+
+func newParseError(parser ValueParser, err error) ParseError {
+	pe := ParseError{Err: err}
+	// a parser describing several values cannot tell which one failed
+	if params := DescribeParser(parser); len(params) == 1 {
+		pe.Location = params[0].Location
+		pe.Name = params[0].Name
+	}
+	return pe
+}
+
+func (e ParseError) Error() string {
+	if e.Name != "" {
+		return e.Name + ": " + e.message()
+	}
+	return e.Err.Error()
+}
+
+// message is the error message without the param name if the error already has it.
+func (e ParseError) message() string {
+	var ve *ParamValueError
+	if errors.As(e.Err, &ve) && ve.Name == e.Name {
+		return ve.valueError()
+	}
+	return e.Err.Error()
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// HttpStatusCode returns the status code of the wrapped error.
+func (e ParseError) HttpStatusCode() int {
+	var se ErrorWithHttpStatus
+	if errors.As(e.Err, &se) {
+		return se.HttpStatusCode
+	}
+	return defaultHttpStatusCodeErrInternal
+}
+
+// parseErrorDetails is the shape of ParseError in the details of the error response.
+type parseErrorDetails struct {
+	Location ParamLocation `json:"location,omitempty" xml:"location,omitempty"`
+	Name     string        `json:"name,omitempty" xml:"name,omitempty"`
+	Error    string        `json:"error" xml:"error"`
+}
+
+func (e ParseError) details() parseErrorDetails {
+	return parseErrorDetails{e.Location, e.Name, e.message()}
+}
+
+func (e ParseError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.details())
+}
+
+func (e ParseError) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(e.details(), start)
+}
+
+// parseErrorsDetails is the shape of ParseErrors in the details of the error response.
+// It is rendered to json as an array and to xml as <details><param>...</param></details>.
+type parseErrorsDetails []parseErrorDetails
+
+func (d parseErrorsDetails) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(struct {
+		Params []parseErrorDetails `xml:"param"`
+	}{d}, start)
+}
+
+// ParseErrors is an error returned when the builder collects errors and some of the parsers failed.
+// The http status code is 422 if all the parsers failed with 422, 400 otherwise.
+// The errors are rendered into the details of the error response.
+type ParseErrors []ParseError
+
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, pe := range e {
+		msgs[i] = pe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, pe := range e {
+		errs[i] = pe
+	}
+	return errs
+}
+
+func (e ParseErrors) HttpStatusCode() int {
+	for _, pe := range e {
+		if pe.HttpStatusCode() != http.StatusUnprocessableEntity {
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusUnprocessableEntity
+}
+
+func (e ParseErrors) WriteHeader(w http.ResponseWriter) {
+	w.WriteHeader(e.HttpStatusCode())
+}
+
+func (e ParseErrors) ErrorDetails() any {
+	details := make(parseErrorsDetails, len(e))
+	for i, pe := range e {
+		details[i] = pe.details()
+	}
+	return details
+}
+
+// dependentParser is implemented by the parsers using the values of other parsers.
+type dependentParser interface {
+	dependent()
+}
+
+// isCollectableError checks if the parser error can be collected with other errors.
+// Only the client errors with 400 and 422 status codes are collected. Other errors,
+// like missing authentication or internal errors, are returned immediately.
+func isCollectableError(err error) bool {
+	if !IsWrappedError(err) {
+		return false
+	}
+	var se ErrorWithHttpStatus
+	if !errors.As(err, &se) {
+		return false
+	}
+	return se.HttpStatusCode == http.StatusBadRequest || se.HttpStatusCode == http.StatusUnprocessableEntity
+}
+
+// collectingMiddleware runs all the parsers collecting their errors into ParseErrors.
+// If any of the parsers failed, the next handler is not called.
+func collectingMiddleware(entries []parserEntry, handlerErrorFunc HandleErrorFunc) MiddlewareFunc {
+	handleError := func(f HandleErrorFunc, w http.ResponseWriter, r *http.Request, err error) {
+		if f == nil {
+			f = handlerErrorFunc
+		}
+		if f == nil {
+			f = DefaultHandlerErrorFunc
+		}
+		f(r.Context(), w, r, err)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var errs ParseErrors
+			for _, e := range entries {
+				// the inputs of a dependent parser may be missing after a failure
+				if _, ok := e.parser.(dependentParser); ok && len(errs) > 0 {
+					continue
+				}
+				newctx, err := e.parser.ParseRequest(r.Context(), w, r)
+				if err == nil {
+					r = r.WithContext(newctx)
+					continue
+				}
+				// parsers with their own error handler and non client errors stop the chain
+				if e.handlerErrorFunc != nil || !isCollectableError(err) {
+					handleError(e.handlerErrorFunc, w, r.WithContext(newctx), err)
+					return
+				}
+				errs = append(errs, newParseError(e.parser, err))
+			}
+			if len(errs) > 0 {
+				handleError(nil, w, r, errs)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package goergohandler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type collectTokenValidator struct{}
+
+func (collectTokenValidator) ValidateToken(ctx context.Context, token string) (*describeUser, bool, error) {
+	return &describeUser{}, token == "valid", nil
+}
+
+func TestBuilder_WithCollectErrors(t *testing.T) {
+	builder := geh.New().WithCollectErrors()
+	geh.AuthParser[describeUser](describeUserKey("user"), geh.TokenBearerFromHeader).Attach(collectTokenValidator{}, builder)
+	limit := geh.QueryParamInt("limit").Attach(builder)
+	bookID := geh.QueryParamInt("book_id").Attach(builder)
+	payload := geh.Payload[testPayload]().Attach(builder)
+
+	called := false
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		require.Equal(t, 10, limit.Get(r))
+		require.Equal(t, 1, bookID.Get(r))
+		require.Equal(t, "value", payload.Get(r).SomeKey)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/?limit=abc", strings.NewReader(`{}`))
+	r.Header.Set("Authorization", "Bearer valid")
+	handler.ServeHTTP(w, r)
+
+	require.False(t, called)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.JSONEq(t, `{
+		"error": "limit: invalid int value: abc; book_id: required query param is missing: book_id; some_key is required",
+		"details": [
+			{"location": "query", "name": "limit", "error": "invalid int value: abc"},
+			{"location": "query", "name": "book_id", "error": "required query param is missing: book_id"},
+			{"location": "body", "error": "some_key is required"}
+		]
+	}`, w.Body.String())
+
+	// authentication errors are not collected
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/?limit=abc", strings.NewReader(`{}`))
+	handler.ServeHTTP(w, r)
+
+	require.False(t, called)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, `{"error":"missing token"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/?limit=10&book_id=1", strings.NewReader(`{"some_key":"value"}`))
+	r.Header.Set("Authorization", "Bearer valid")
+	handler.ServeHTTP(w, r)
+
+	require.True(t, called)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestParseErrors(t *testing.T) {
+	errs := geh.ParseErrors{
+		{Location: geh.ParamLocationQuery, Name: "limit", Err: geh.NewError(http.StatusUnprocessableEntity, errors.New("too big"))},
+		{Location: geh.ParamLocationBody, Err: geh.NewError(http.StatusUnprocessableEntity, geh.ErrPayloadParsing)},
+	}
+	require.Equal(t, http.StatusUnprocessableEntity, errs.HttpStatusCode())
+	require.ErrorIs(t, errs, geh.ErrPayloadParsing)
+
+	errs[0].Err = geh.NewError(http.StatusBadRequest, errors.New("invalid"))
+	require.Equal(t, http.StatusBadRequest, errs.HttpStatusCode())
+}
@@ -0,0 +1,213 @@
+package goergohandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+const (
+	defaultHttpStatusCodeErrPatchParsing          = http.StatusBadRequest
+	defaultHttpStatusCodeErrPatchConflict         = http.StatusConflict
+	defaultHttpStatusCodeErrPatchValidation       = http.StatusBadRequest
+	defaultHttpStatusCodeErrPatchUnsupportedMedia = http.StatusUnsupportedMediaType
+
+	MediaTypeMergePatch = "application/merge-patch+json"
+	MediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	ErrPatchParsing = errors.New("error parsing patch")
+	// Returned when the JSON Patch cannot be applied to the target, e.g. the path is missing or the test operation fails.
+	ErrPatchConflict = errors.New("patch cannot be applied")
+)
+
+type PatchPayloadType[T any] struct {
+	target      ContextGetter[T]
+	maxBodySize int64
+}
+
+// PatchPayload is a parser that applies the patch from the request body to the value of the target parser.
+// The format is picked by the Content-Type: application/merge-patch+json (RFC 7396, also used for application/json
+// and when Content-Type is missing) or application/json-patch+json (RFC 6902).
+// Malformed patches are rejected with ErrPatchParsing and 400 status code, JSON Patches which cannot be applied
+// with ErrPatchConflict and 409 status code. The patched value is validated if it implements WithValidation.
+// The target is not modified. The unexported fields and the fields tagged with json:"-" are not in the patched
+// document, the patched value gets them from the target. The parser must be attached after the target.
+// Example:
+//
+//	book := geh.Derive1(bookID, useCase.GetBook).Attach(builder)
+//	patched := geh.PatchPayload(book).Attach(builder)
+func PatchPayload[T any](target ContextGetter[T]) *PatchPayloadType[T] {
+	return &PatchPayloadType[T]{target: target}
+}
+
+// WithMaxBodySize limits the size of the patch. Bigger patches are rejected with ErrPayloadTooLarge and 413 status code.
+func (p *PatchPayloadType[T]) WithMaxBodySize(n int64) *PatchPayloadType[T] {
+	p.maxBodySize = n
+	return p
+}
+
+func (p *PatchPayloadType[T]) Attach(builder ParserAdder) *AttachedPatchPayload[T] {
+	a := &AttachedPatchPayload[T]{p}
+	builder.AddParser(a)
+	return a
+}
+
+type AttachedPatchPayload[T any] struct {
+	p *PatchPayloadType[T]
+}
+
+func (a *AttachedPatchPayload[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	mediaType := MediaTypeMergePatch
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return ctx, WrapWithStatusCode(ErrPayloadUnsupportedMediaType, defaultHttpStatusCodeErrPatchUnsupportedMedia)
+		}
+	}
+
+	body, err := bufferBody(w, r, a.p.maxBodySize)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return ctx, WrapWithStatusCode(ErrPayloadTooLarge, defaultHttpStatusCodeErrPayloadTooLarge)
+		}
+		return ctx, WrapWithStatusCode(ErrPatchParsing, defaultHttpStatusCodeErrPatchParsing)
+	}
+	r.Body = newBufferedBody(body)
+
+	original := a.p.target.GetContext(ctx)
+	target, err := json.Marshal(original)
+	if err != nil {
+		return ctx, NewInternalServerError(err)
+	}
+	doc, err := decodeJSONDocument(target)
+	if err != nil {
+		return ctx, NewInternalServerError(err)
+	}
+
+	switch mediaType {
+	case MediaTypeMergePatch, mediaTypeJSON:
+		patch, err := decodeJSONDocument(body)
+		if err != nil {
+			return ctx, newPatchParsingError(err)
+		}
+		doc = applyMergePatch(doc, patch)
+	case MediaTypeJSONPatch:
+		ops, err := parseJSONPatch(body)
+		if err != nil {
+			return ctx, newPatchParsingError(err)
+		}
+		doc, err = applyJSONPatch(doc, ops)
+		if err != nil {
+			return ctx, WrapWithStatusCode(fmt.Errorf("%w: %w", ErrPatchConflict, err), defaultHttpStatusCodeErrPatchConflict)
+		}
+	default:
+		return ctx, WrapWithStatusCode(ErrPayloadUnsupportedMediaType, defaultHttpStatusCodeErrPatchUnsupportedMedia)
+	}
+
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return ctx, NewInternalServerError(err)
+	}
+	var decoded T
+	if err := json.Unmarshal(patched, &decoded); err != nil {
+		return ctx, newPatchParsingError(err)
+	}
+	v := withJSONFields(original, decoded)
+	if err := ValidateWithValidation(v); err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrPatchValidation)
+	}
+	return context.WithValue(ctx, a, v), nil
+}
+
+// newPatchParsingError wraps the error of the patch decoding with ErrPatchParsing.
+// Type errors name the field instead of the position which would point into the patched document.
+func newPatchParsingError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		err = fmt.Errorf("%s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	return WrapWithStatusCode(fmt.Errorf("%w: %w", ErrPatchParsing, err), defaultHttpStatusCodeErrPatchParsing)
+}
+
+// withJSONFields returns a copy of the original with the fields encoding/json decodes taken from decoded.
+// The original is not modified: its pointers, maps and slices are not decoded into.
+func withJSONFields[T any](original, decoded T) T {
+	dst := reflect.ValueOf(&original).Elem()
+	src := reflect.ValueOf(decoded)
+	if dst.Kind() == reflect.Pointer && dst.Type().Elem().Kind() == reflect.Struct {
+		if dst.IsNil() || src.IsNil() {
+			return decoded
+		}
+		copied := reflect.New(dst.Type().Elem())
+		copied.Elem().Set(dst.Elem())
+		dst.Set(copied)
+		dst, src = dst.Elem(), src.Elem()
+	}
+	if dst.Kind() != reflect.Struct {
+		return decoded
+	}
+	copyJSONFields(dst, src)
+	return original
+}
+
+// copyJSONFields sets the fields of dst encoding/json decodes to the values of src.
+func copyJSONFields(dst, src reflect.Value) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			copyJSONFields(dst.Field(i), src.Field(i))
+			continue
+		}
+		if f.IsExported() && dst.Field(i).CanSet() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
+
+func (a *AttachedPatchPayload[T]) maxBodySize() int64 {
+	return a.p.maxBodySize
+}
+
+// dependent marks the parser as depending on the value of the target parser.
+func (a *AttachedPatchPayload[T]) dependent() {}
+
+func (a *AttachedPatchPayload[T]) Describe() []ParamDescription {
+	statuses := []int{
+		defaultHttpStatusCodeErrPatchParsing,
+		defaultHttpStatusCodeErrPatchConflict,
+		defaultHttpStatusCodeErrPatchValidation,
+		defaultHttpStatusCodeErrPatchUnsupportedMedia,
+	}
+	if a.p.maxBodySize > 0 {
+		statuses = append(statuses, defaultHttpStatusCodeErrPayloadTooLarge)
+	}
+	return []ParamDescription{{
+		Location:      ParamLocationBody,
+		Type:          typeOf[T](),
+		Required:      true,
+		MediaTypes:    []string{mediaTypeJSON, MediaTypeJSONPatch, MediaTypeMergePatch},
+		ErrorStatuses: uniqueStatuses(statuses...),
+	}}
+}
+
+// Get returns the patched value.
+func (a *AttachedPatchPayload[T]) Get(r *http.Request) T {
+	return a.GetContext(r.Context())
+}
+
+func (a *AttachedPatchPayload[T]) GetContext(ctx context.Context) T {
+	return GetFromContext[T](ctx, a)
+}
@@ -0,0 +1,229 @@
+package goergohandler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type patchBook struct {
+	Title       string   `json:"title"`
+	Description *string  `json:"description"`
+	Price       int      `json:"price"`
+	Tags        []string `json:"tags"`
+}
+
+func (b patchBook) Validate() error {
+	if b.Title == "" {
+		return errors.New("title is required")
+	}
+	return nil
+}
+
+type bookPatch struct {
+	Title       geh.Optional[string] `json:"title"`
+	Description geh.Optional[string] `json:"description"`
+	Price       geh.Optional[int]    `json:"price,omitzero"`
+}
+
+func TestOptional(t *testing.T) {
+	var patch bookPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"title":"Dune","description":null}`), &patch))
+
+	require.Equal(t, geh.OptionalValue("Dune"), patch.Title)
+	require.Equal(t, geh.OptionalNull[string](), patch.Description)
+	require.False(t, patch.Price.Set)
+
+	title, ok := patch.Title.Get()
+	require.True(t, ok)
+	require.Equal(t, "Dune", title)
+	_, ok = patch.Description.Get()
+	require.False(t, ok)
+
+	description, price := "old", 10
+	patch.Description.Apply(&description)
+	patch.Price.Apply(&price)
+	require.Equal(t, "", description)
+	require.Equal(t, 10, price)
+
+	data, err := json.Marshal(patch)
+	require.NoError(t, err)
+	require.Equal(t, `{"title":"Dune","description":null}`, string(data))
+}
+
+func makePatchHandler() http.Handler {
+	description := "A novel"
+	builder := geh.New()
+	book := geh.Derive(func(ctx context.Context) (patchBook, error) {
+		return patchBook{Title: "Dune", Description: &description, Price: 10, Tags: []string{"sci-fi"}}, nil
+	}).Attach(builder)
+	patched := geh.PatchPayload(book).Attach(builder)
+
+	return builder.BuildHandlerWrapped(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return patched.Get(r), nil
+	})
+}
+
+func TestPatchPayload(t *testing.T) {
+	handler := makePatchHandler()
+
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		resp        string
+	}{
+		{
+			"merge patch",
+			geh.MediaTypeMergePatch,
+			`{"price":12,"description":null,"tags":["classic"]}`,
+			http.StatusOK,
+			`{"result":{"title":"Dune","description":null,"price":12,"tags":["classic"]}}`,
+		},
+		{
+			"merge patch without content type",
+			"",
+			`{"title":"Dune Messiah"}`,
+			http.StatusOK,
+			`{"result":{"title":"Dune Messiah","description":"A novel","price":10,"tags":["sci-fi"]}}`,
+		},
+		{
+			"merge patch wrong type",
+			geh.MediaTypeMergePatch,
+			`{"price":"free"}`,
+			http.StatusBadRequest,
+			`{"error":"error parsing patch: price: expected int, got string"}`,
+		},
+		{
+			"merge patch validation",
+			geh.MediaTypeMergePatch,
+			`{"title":null}`,
+			http.StatusBadRequest,
+			`{"error":"title is required"}`,
+		},
+		{
+			"json patch",
+			geh.MediaTypeJSONPatch,
+			`[
+				{"op":"test","path":"/price","value":10.0},
+				{"op":"replace","path":"/price","value":15},
+				{"op":"add","path":"/tags/0","value":"classic"},
+				{"op":"add","path":"/tags/-","value":"desert"},
+				{"op":"copy","from":"/title","path":"/description"}
+			]`,
+			http.StatusOK,
+			`{"result":{"title":"Dune","description":"Dune","price":15,"tags":["classic","sci-fi","desert"]}}`,
+		},
+		{
+			"json patch move and remove",
+			geh.MediaTypeJSONPatch,
+			`[{"op":"move","from":"/description","path":"/title"},{"op":"remove","path":"/tags/0"}]`,
+			http.StatusOK,
+			`{"result":{"title":"A novel","description":null,"price":10,"tags":[]}}`,
+		},
+		{
+			"json patch test failed",
+			geh.MediaTypeJSONPatch,
+			`[{"op":"test","path":"/price","value":11},{"op":"replace","path":"/price","value":15}]`,
+			http.StatusConflict,
+			`{"error":"patch cannot be applied: operation 0 (test): test failed at /price"}`,
+		},
+		{
+			"json patch missing path",
+			geh.MediaTypeJSONPatch,
+			`[{"op":"add","path":"/author/name","value":"Frank"}]`,
+			http.StatusConflict,
+			`{"error":"patch cannot be applied: operation 0 (add): path not found: /author"}`,
+		},
+		{
+			"json patch unknown op",
+			geh.MediaTypeJSONPatch,
+			`[{"op":"merge","path":"/title"}]`,
+			http.StatusBadRequest,
+			`{"error":"error parsing patch: operation 0: unknown op \"merge\""}`,
+		},
+		{
+			"unsupported media type",
+			"text/plain",
+			`title=Dune`,
+			http.StatusUnsupportedMediaType,
+			`{"error":"unsupported media type"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+}
+
+type patchAccount struct {
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels"`
+	Password string            `json:"-"`
+	version  int
+}
+
+func (a patchAccount) Version() int {
+	return a.version
+}
+
+func TestPatchPayload_FieldsNotInJSON(t *testing.T) {
+	account := patchAccount{Name: "rob", Labels: map[string]string{"team": "a"}, Password: "secret", version: 3}
+	builder := geh.New()
+	target := geh.Derive(func(ctx context.Context) (patchAccount, error) {
+		return account, nil
+	}).Attach(builder)
+	patched := geh.PatchPayload(target).Attach(builder)
+
+	var got patchAccount
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		got = patched.Get(r)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"ann","labels":{"role":"admin"}}`))
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "ann", got.Name)
+	require.Equal(t, map[string]string{"team": "a", "role": "admin"}, got.Labels)
+	require.Equal(t, "secret", got.Password)
+	require.Equal(t, 3, got.Version())
+	// the target is not modified
+	require.Equal(t, map[string]string{"team": "a"}, account.Labels)
+}
+
+func TestPatchPayload_MaxBodySize(t *testing.T) {
+	builder := geh.New()
+	target := geh.Derive(func(ctx context.Context) (patchBook, error) {
+		return patchBook{Title: "Dune"}, nil
+	}).Attach(builder)
+	geh.PatchPayload(target).WithMaxBodySize(16).Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"title":"Dune Messiah"}`)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Equal(t, `{"error":"payload is too large"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"price":1}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+}
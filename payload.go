@@ -1,28 +1,45 @@
 package goergohandler
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"reflect"
+	"strings"
 )
 
 const (
-	defaultHttpStatusCodeErrPayloadParsing    = http.StatusBadRequest
-	defaultHttpStatusCodeErrPayloadValidation = http.StatusBadRequest
+	defaultHttpStatusCodeErrPayloadParsing          = http.StatusBadRequest
+	defaultHttpStatusCodeErrPayloadValidation       = http.StatusBadRequest
+	defaultHttpStatusCodeErrPayloadUnsupportedMedia = http.StatusUnsupportedMediaType
+	defaultHttpStatusCodeErrPayloadTooLarge         = http.StatusRequestEntityTooLarge
+	defaultHttpStatusCodeErrPayloadStrict           = http.StatusBadRequest
 )
 
-type payloadKeyType string
-
 var (
-	payloadKey        payloadKeyType = "payload"
-	ErrPayloadParsing error          = errors.New("error parsing payload")
+	ErrPayloadParsing error = errors.New("error parsing payload")
+	// Returned when there is no decoder for the Content-Type of the request.
+	ErrPayloadUnsupportedMediaType = errors.New("unsupported media type")
+	// Returned when the body is bigger than the limit set by WithMaxBodySize.
+	ErrPayloadTooLarge = errors.New("payload is too large")
+	// Returned by the strict json decoding when the payload has a field missing in the type.
+	ErrPayloadUnknownField = errors.New("unknown field")
+	// Returned by the strict json decoding when there is data after the json value.
+	ErrPayloadTrailingData = errors.New("unexpected data after payload")
 )
 
 type PayloadParserType[T any] struct {
 	// override the default parsing error
 	// TODO: implement constructor for this
 	ParserErr error
+	// nil means the default decoders
+	decoders PayloadDecoders
+	// zero means no limit
+	maxBodySize int64
+	// options merged into the JSONDecoder
+	json JSONDecoder
 }
 
 func PayloadAttach[T any](builder ParserAdder) *AttachedPayloadParser[T] {
@@ -30,6 +47,9 @@ func PayloadAttach[T any](builder ParserAdder) *AttachedPayloadParser[T] {
 }
 
 // Payload is a parser that parses the payload from the request.
+// The decoder is picked by the Content-Type of the request: json (also used when Content-Type is missing),
+// xml and application/x-www-form-urlencoded are supported by default. Use WithDecoder to register more.
+// Unknown media types are rejected with ErrPayloadUnsupportedMediaType and 415 status code.
 // If payload type implements WithValidation, it will be validated.
 func Payload[T any]() *PayloadParserType[T] {
 	return &PayloadParserType[T]{}
@@ -41,7 +61,51 @@ func (p *PayloadParserType[T]) Attach(builder ParserAdder) *AttachedPayloadParse
 	return a
 }
 
-// WithParsingError sets the error to be returned if the payload is not valid json.
+// WithDecoder registers the decoder for the media type replacing the existing one.
+// Example:
+//
+//	geh.Payload[book]().WithDecoder("application/msgpack", msgpackDecoder)
+func (p *PayloadParserType[T]) WithDecoder(mediaType string, decoder PayloadDecoder) *PayloadParserType[T] {
+	if p.decoders == nil {
+		p.decoders = DefaultPayloadDecoders()
+	}
+	p.decoders[strings.ToLower(mediaType)] = decoder
+	return p
+}
+
+// WithDecoders replaces all the decoders of the parser.
+func (p *PayloadParserType[T]) WithDecoders(decoders PayloadDecoders) *PayloadParserType[T] {
+	p.decoders = decoders
+	return p
+}
+
+// WithMaxBodySize limits the size of the body. Bigger payloads are rejected with ErrPayloadTooLarge and 413 status code.
+func (p *PayloadParserType[T]) WithMaxBodySize(n int64) *PayloadParserType[T] {
+	p.maxBodySize = n
+	return p
+}
+
+// DisallowUnknownFields makes the json decoding fail with ErrPayloadUnknownField if the payload
+// has a field missing in T.
+func (p *PayloadParserType[T]) DisallowUnknownFields() *PayloadParserType[T] {
+	p.json.DisallowUnknownFields = true
+	return p
+}
+
+// DisallowTrailingData makes the json decoding fail with ErrPayloadTrailingData if there is anything
+// but whitespace after the json value.
+func (p *PayloadParserType[T]) DisallowTrailingData() *PayloadParserType[T] {
+	p.json.DisallowTrailingData = true
+	return p
+}
+
+// UseNumber makes the json decoding put numbers into interface{} fields as json.Number instead of float64.
+func (p *PayloadParserType[T]) UseNumber() *PayloadParserType[T] {
+	p.json.UseNumber = true
+	return p
+}
+
+// WithParsingError sets the error to be returned if the payload cannot be decoded.
 func (p *PayloadParserType[T]) WithParsingError(err error) *PayloadParserType[T] {
 	p.ParserErr = err
 	return p
@@ -53,19 +117,155 @@ type AttachedPayloadParser[T any] struct {
 
 func (p *AttachedPayloadParser[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
 	var pl T
-	err := json.NewDecoder(r.Body).Decode(&pl)
+	err := decodePayload(w, r, &pl, p.pp.options())
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, p, pl), nil
+}
+
+func (p *PayloadParserType[T]) options() payloadOptions {
+	return payloadOptions{
+		decoders:    p.decoders,
+		parserErr:   p.ParserErr,
+		maxBodySize: p.maxBodySize,
+		json:        p.json,
+	}
+}
+
+// payloadOptions configure decodePayload. The zero value means the default decoders and no limits.
+type payloadOptions struct {
+	decoders PayloadDecoders
+	// overrides ErrPayloadParsing if not nil
+	parserErr   error
+	maxBodySize int64
+	json        JSONDecoder
+}
+
+// decodePayload decodes the request body into v with the decoder matching the Content-Type
+// and validates the value if it implements WithValidation.
+func decodePayload(w http.ResponseWriter, r *http.Request, v any, opts payloadOptions) error {
+	decoders := opts.decoders
+	if decoders == nil {
+		decoders = defaultPayloadDecoders
+	}
+	decoder, ok := decoders.decoderFor(r)
+	if !ok {
+		return WrapWithStatusCode(ErrPayloadUnsupportedMediaType, defaultHttpStatusCodeErrPayloadUnsupportedMedia)
+	}
+	if jsonDecoder, ok := decoder.(JSONDecoder); ok {
+		decoder = jsonDecoder.merge(opts.json)
+	}
+	body, err := bufferBody(w, r, opts.maxBodySize)
 	if err != nil {
-		parseErr := ErrPayloadParsing
-		if p.pp.ParserErr != nil {
-			parseErr = p.pp.ParserErr
+		return bodyReadError(err)
+	}
+	// every payload parser decodes its own copy so the body can be read again by the next one
+	r.Body = newBufferedBody(body)
+	err = decoder.Decode(r, v)
+	r.Body = newBufferedBody(body)
+	if err != nil {
+		if errors.Is(err, ErrPayloadUnknownField) || errors.Is(err, ErrPayloadTrailingData) {
+			return WrapWithStatusCode(err, defaultHttpStatusCodeErrPayloadStrict)
+		}
+		if opts.parserErr != nil {
+			return WrapWithStatusCode(opts.parserErr, defaultHttpStatusCodeErrPayloadParsing)
 		}
-		return ctx, WrapWithStatusCode(parseErr, defaultHttpStatusCodeErrPayloadParsing)
+		var decodeErr *PayloadDecodeError
+		if errors.As(err, &decodeErr) {
+			return WrapWithStatusCode(decodeErr, defaultHttpStatusCodeErrPayloadParsing)
+		}
+		return WrapWithStatusCode(ErrPayloadParsing, defaultHttpStatusCodeErrPayloadParsing)
 	}
-	err = ValidateWithValidation(pl)
+	err = ValidateWithValidation(reflect.ValueOf(v).Elem().Interface())
 	if err != nil {
-		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrPayloadValidation)
+		return WrapWithStatusCode(err, defaultHttpStatusCodeErrPayloadValidation)
+	}
+	return nil
+}
+
+// bufferedBody is the request body read into memory.
+type bufferedBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func newBufferedBody(data []byte) *bufferedBody {
+	return &bufferedBody{bytes.NewReader(data), data}
+}
+
+func (b *bufferedBody) Close() error {
+	return nil
+}
+
+// bufferBody reads the body of the request into memory unless it was already done by another payload parser.
+// Bodies bigger than maxSize fail with *http.MaxBytesError. Zero maxSize means no limit.
+func bufferBody(w http.ResponseWriter, r *http.Request, maxSize int64) ([]byte, error) {
+	if b, ok := r.Body.(*bufferedBody); ok {
+		if maxSize > 0 && int64(len(b.data)) > maxSize {
+			return nil, &http.MaxBytesError{Limit: maxSize}
+		}
+		return b.data, nil
+	}
+	if r.Body == nil {
+		return nil, nil
+	}
+	body := r.Body
+	if maxSize > 0 {
+		body = http.MaxBytesReader(w, body, maxSize)
+	}
+	return io.ReadAll(body)
+}
+
+// bodyReadError converts the error of bufferBody to ErrPayloadTooLarge or ErrPayloadParsing.
+func bodyReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return WrapWithStatusCode(ErrPayloadTooLarge, defaultHttpStatusCodeErrPayloadTooLarge)
+	}
+	return WrapWithStatusCode(ErrPayloadParsing, defaultHttpStatusCodeErrPayloadParsing)
+}
+
+// bodyReader is implemented by the parsers reading the request body into memory.
+type bodyReader interface {
+	// maxBodySize is the limit the parser reads the body with, zero or negative means no limit.
+	maxBodySize() int64
+}
+
+// multipartReader is implemented by the parsers reading the multipart form. The files of the form
+// are spooled to disk, so concurrent groups run these parsers on the original body instead of buffering it.
+type multipartReader interface {
+	readsMultipart()
+}
+
+func (p *AttachedPayloadParser[T]) maxBodySize() int64 {
+	return p.pp.maxBodySize
+}
+
+func (p *AttachedPayloadParser[T]) Describe() []ParamDescription {
+	return []ParamDescription{describePayload(typeOf[T](), p.pp.options())}
+}
+
+func describePayload(t reflect.Type, opts payloadOptions) ParamDescription {
+	decoders := opts.decoders
+	if decoders == nil {
+		decoders = defaultPayloadDecoders
+	}
+	statuses := []int{
+		defaultHttpStatusCodeErrPayloadParsing,
+		defaultHttpStatusCodeErrPayloadValidation,
+		defaultHttpStatusCodeErrPayloadUnsupportedMedia,
+	}
+	if opts.maxBodySize > 0 {
+		statuses = append(statuses, defaultHttpStatusCodeErrPayloadTooLarge)
+	}
+	return ParamDescription{
+		Location:      ParamLocationBody,
+		Type:          t,
+		Required:      true,
+		MediaTypes:    decoders.mediaTypes(),
+		ErrorStatuses: uniqueStatuses(statuses...),
 	}
-	return context.WithValue(ctx, payloadKey, pl), nil
 }
 
 func (p *AttachedPayloadParser[T]) Get(r *http.Request) T {
@@ -73,5 +273,5 @@ func (p *AttachedPayloadParser[T]) Get(r *http.Request) T {
 }
 
 func (p *AttachedPayloadParser[T]) GetContext(ctx context.Context) T {
-	return GetFromContext[T](ctx, payloadKey)
+	return GetFromContext[T](ctx, p)
 }
@@ -0,0 +1,106 @@
+package goergohandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// PayloadDecodeError describes why the json payload cannot be decoded. It matches ErrPayloadParsing
+// with errors.Is and renders the position of the error into the details of the error response:
+//
+//	{"error":"error parsing payload: price: expected int, got string","details":{"field":"price","expected":"int","actual":"string","offset":12,"line":1,"column":12}}
+type PayloadDecodeError struct {
+	// Path of the field in the payload, e.g. author.name. Empty for syntax errors.
+	Field string
+	// Go type of the field.
+	Expected string
+	// Kind of the json value: string, number, object, array, bool. The values for the form fields.
+	Actual string
+	// Byte offset in the body the error was detected at. For type errors it is the end of the value.
+	// Zero for the form fields.
+	Offset int64
+	// 1-based position of the error in the body. Zero for the form fields.
+	Line, Column int
+	// The error returned by encoding/json.
+	Err error
+}
+
+type payloadDecodeErrorDetails struct {
+	Field    string `json:"field,omitempty" xml:"field,omitempty"`
+	Expected string `json:"expected,omitempty" xml:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty" xml:"actual,omitempty"`
+	Offset   int64  `json:"offset,omitempty" xml:"offset,omitempty"`
+	Line     int    `json:"line,omitempty" xml:"line,omitempty"`
+	Column   int    `json:"column,omitempty" xml:"column,omitempty"`
+}
+
+func (e *PayloadDecodeError) Error() string {
+	if e.Expected != "" {
+		if e.Field == "" {
+			return fmt.Sprintf("%s: expected %s, got %s", ErrPayloadParsing, e.Expected, e.Actual)
+		}
+		return fmt.Sprintf("%s: %s: expected %s, got %s", ErrPayloadParsing, e.Field, e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("%s: %s at line %d, column %d", ErrPayloadParsing, e.Err, e.Line, e.Column)
+}
+
+func (e *PayloadDecodeError) Is(target error) bool {
+	return target == ErrPayloadParsing
+}
+
+func (e *PayloadDecodeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PayloadDecodeError) ErrorDetails() any {
+	return payloadDecodeErrorDetails{
+		Field:    e.Field,
+		Expected: e.Expected,
+		Actual:   e.Actual,
+		Offset:   e.Offset,
+		Line:     e.Line,
+		Column:   e.Column,
+	}
+}
+
+// newPayloadDecodeError converts the error of encoding/json into PayloadDecodeError.
+// read is the part of the body consumed by the decoder, it is used to get the line and column.
+// Other errors are returned as is.
+func newPayloadDecodeError(err error, read []byte) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		e         *PayloadDecodeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		e = &PayloadDecodeError{Offset: syntaxErr.Offset, Err: err}
+	case errors.As(err, &typeErr):
+		e = &PayloadDecodeError{
+			Field:    typeErr.Field,
+			Expected: typeErr.Type.String(),
+			Actual:   typeErr.Value,
+			Offset:   typeErr.Offset,
+			Err:      err,
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		e = &PayloadDecodeError{Offset: int64(len(read)), Err: err}
+	default:
+		return err
+	}
+	e.Line, e.Column = lineColumn(read, e.Offset)
+	return e
+}
+
+// lineColumn returns the 1-based line and column of the last byte before the offset in data,
+// which is the byte the decoder failed at.
+func lineColumn(data []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	prefix := data[:offset]
+	line := bytes.Count(prefix, []byte("\n")) + 1
+	column := int(offset) - (bytes.LastIndexByte(prefix, '\n') + 1)
+	return line, max(column, 1)
+}
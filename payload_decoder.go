@@ -0,0 +1,297 @@
+package goergohandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	mediaTypeForm = "application/x-www-form-urlencoded"
+)
+
+// PayloadDecoder decodes the request body into the value pointed by v.
+type PayloadDecoder interface {
+	Decode(r *http.Request, v any) error
+}
+
+// PayloadDecoderFunc is a function implementing PayloadDecoder.
+type PayloadDecoderFunc func(r *http.Request, v any) error
+
+func (f PayloadDecoderFunc) Decode(r *http.Request, v any) error {
+	return f(r, v)
+}
+
+// JSONDecoder decodes the json body. Syntax and type errors are returned as PayloadDecodeError.
+type JSONDecoder struct {
+	// Fail with ErrPayloadUnknownField if the payload has a field missing in the type.
+	DisallowUnknownFields bool
+	// Fail with ErrPayloadTrailingData if there is anything but whitespace after the json value.
+	DisallowTrailingData bool
+	// Decode numbers into interface{} fields as json.Number.
+	UseNumber bool
+}
+
+func (d JSONDecoder) Decode(r *http.Request, v any) error {
+	// keep the consumed part of the body to locate the decoding errors
+	var read bytes.Buffer
+	dec := json.NewDecoder(io.TeeReader(r.Body, &read))
+	if d.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if d.UseNumber {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
+		decodeErr := newPayloadDecodeError(err, read.Bytes())
+		var payloadErr *PayloadDecodeError
+		if errors.As(decodeErr, &payloadErr) || !d.DisallowUnknownFields {
+			return decodeErr
+		}
+		return unknownFieldError(&read, r.Body, v)
+	}
+	if d.DisallowTrailingData {
+		if _, err := dec.Token(); err != io.EOF {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return err
+			}
+			return ErrPayloadTrailingData
+		}
+	}
+	return nil
+}
+
+// unknownFieldError checks the error of the decoding with DisallowUnknownFields is caused by an unknown field.
+// encoding/json has no typed error for it, so the value is decoded again without the check:
+// if it succeeds, the error is ErrPayloadUnknownField with the path of the field missing in the type,
+// otherwise the error of the second decoding is returned.
+// read is the consumed part of the body, the rest is read from body.
+func unknownFieldError(read *bytes.Buffer, body io.Reader, v any) error {
+	if _, readErr := read.ReadFrom(body); readErr != nil {
+		return readErr
+	}
+	t := reflect.TypeOf(v).Elem()
+	dec := json.NewDecoder(bytes.NewReader(read.Bytes()))
+	if decodeErr := dec.Decode(reflect.New(t).Interface()); decodeErr != nil {
+		return newPayloadDecodeError(decodeErr, read.Bytes())
+	}
+	if path, ok := findUnknownField(read.Bytes(), t); ok {
+		return fmt.Errorf("%w %q", ErrPayloadUnknownField, path)
+	}
+	return ErrPayloadUnknownField
+}
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// findUnknownField returns the path of the first object key of the document which has no field in the type t:
+// "author.nick", "tags[1].name". Types implementing json.Unmarshaler are not checked.
+func findUnknownField(data []byte, t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return "", false
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := jsonFields(t)
+		keys, values, ok := jsonObject(data)
+		if !ok {
+			return "", false
+		}
+		for i, key := range keys {
+			ft, known := fields[key]
+			if !known {
+				// encoding/json matches the keys case insensitively
+				for name, f := range fields {
+					if strings.EqualFold(name, key) {
+						ft, known = f, true
+						break
+					}
+				}
+			}
+			if !known {
+				return key, true
+			}
+			if path, ok := findUnknownField(values[i], ft); ok {
+				return joinFieldPath(key, path), true
+			}
+		}
+	case reflect.Map:
+		keys, values, ok := jsonObject(data)
+		if !ok {
+			return "", false
+		}
+		for i, key := range keys {
+			if path, ok := findUnknownField(values[i], t.Elem()); ok {
+				return joinFieldPath(key, path), true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return "", false
+		}
+		for i, item := range items {
+			if path, ok := findUnknownField(item, t.Elem()); ok {
+				return joinFieldPath(fmt.Sprintf("[%d]", i), path), true
+			}
+		}
+	}
+	return "", false
+}
+
+func joinFieldPath(prefix, path string) string {
+	if strings.HasPrefix(path, "[") {
+		return prefix + path
+	}
+	return prefix + "." + path
+}
+
+// jsonObject returns the keys of the json object in the document order and their values.
+func jsonObject(data []byte) ([]string, []json.RawMessage, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, false
+	}
+	var (
+		keys   []string
+		values []json.RawMessage
+	)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, false
+		}
+		keys = append(keys, tok.(string))
+		values = append(values, value)
+	}
+	return keys, values, true
+}
+
+// jsonFields returns the json names of the fields of the struct type t and their types.
+// The fields of the embedded structs without a name in the tag are promoted like encoding/json does.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = ft
+	}
+	return fields
+}
+
+// merge returns the decoder with the options enabled in either of the decoders.
+func (d JSONDecoder) merge(o JSONDecoder) JSONDecoder {
+	return JSONDecoder{
+		DisallowUnknownFields: d.DisallowUnknownFields || o.DisallowUnknownFields,
+		DisallowTrailingData:  d.DisallowTrailingData || o.DisallowTrailingData,
+		UseNumber:             d.UseNumber || o.UseNumber,
+	}
+}
+
+// XMLDecoder decodes the xml body.
+type XMLDecoder struct{}
+
+func (XMLDecoder) Decode(r *http.Request, v any) error {
+	return xml.NewDecoder(r.Body).Decode(v)
+}
+
+// FormDecoder decodes the application/x-www-form-urlencoded body into a struct.
+// The fields are matched by the form tag: `form:"title"`. Field types must implement WithParser,
+// encoding.TextUnmarshaler or be one of the basic types, pointers or slices of them.
+// The values which cannot be parsed are returned as PayloadDecodeError.
+type FormDecoder struct{}
+
+func (FormDecoder) Decode(r *http.Request, v any) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	err := decodeValues(r.Context(), r.PostForm, v, "form")
+	var fieldErr *valuesFieldError
+	if errors.As(err, &fieldErr) {
+		return &PayloadDecodeError{
+			Field:    fieldErr.key,
+			Expected: fieldErr.t.String(),
+			Actual:   strings.Join(fieldErr.vals, ","),
+			Err:      err,
+		}
+	}
+	return err
+}
+
+// PayloadDecoders maps media types to the decoders.
+type PayloadDecoders map[string]PayloadDecoder
+
+// defaultPayloadDecoders are used by the payload parsers unless WithDecoder is called.
+var defaultPayloadDecoders = PayloadDecoders{
+	mediaTypeJSON: JSONDecoder{},
+	mediaTypeXML:  XMLDecoder{},
+	"text/xml":    XMLDecoder{},
+	mediaTypeForm: FormDecoder{},
+}
+
+// DefaultPayloadDecoders returns a copy of the decoders used by the payload parsers by default:
+// json, xml and application/x-www-form-urlencoded.
+func DefaultPayloadDecoders() PayloadDecoders {
+	return maps.Clone(defaultPayloadDecoders)
+}
+
+// decoderFor returns the decoder for the Content-Type of the request.
+// Requests without Content-Type are decoded as json.
+func (d PayloadDecoders) decoderFor(r *http.Request) (PayloadDecoder, bool) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		dec, ok := d[mediaTypeJSON]
+		return dec, ok
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	dec, ok := d[mediaType]
+	return dec, ok
+}
+
+// mediaTypes returns the sorted media types of the decoders.
+func (d PayloadDecoders) mediaTypes() []string {
+	return slices.Sorted(maps.Keys(d))
+}
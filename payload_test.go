@@ -2,8 +2,11 @@ package goergohandler_test
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -61,3 +64,241 @@ func TestPayloadWithCustomParserError(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, "custom parser error", err.Error())
 }
+
+type codecPayload struct {
+	Title string   `json:"title" xml:"title" form:"title"`
+	Price int      `json:"price" xml:"price" form:"price"`
+	Tags  []string `json:"tags" xml:"tag" form:"tag"`
+}
+
+func (p codecPayload) Validate() error {
+	if p.Price <= 0 {
+		return errors.New("invalid price")
+	}
+	return nil
+}
+
+func TestPayload_Decoders(t *testing.T) {
+	payload := goergohandler.Payload[codecPayload]().
+		WithDecoder("text/plain", goergohandler.PayloadDecoderFunc(func(r *http.Request, v any) error {
+			bs, err := io.ReadAll(r.Body)
+			if err != nil {
+				return err
+			}
+			title, price, _ := strings.Cut(string(bs), ":")
+			p := v.(*codecPayload)
+			p.Title = title
+			p.Price, err = strconv.Atoi(price)
+			return err
+		}))
+	builder := goergohandler.New()
+	attachedPayload := payload.Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		pl := attachedPayload.Get(r)
+		fmt.Fprintf(w, "%s %d %v", pl.Title, pl.Price, pl.Tags)
+	})
+
+	cases := []struct {
+		name         string
+		contentType  string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"json", "application/json; charset=utf-8", `{"title":"Dune","price":10,"tags":["a","b"]}`, http.StatusOK, "Dune 10 [a b]"},
+		{"no content type", "", `{"title":"Dune","price":10}`, http.StatusOK, "Dune 10 []"},
+		{"xml", "application/xml", `<book><title>Dune</title><price>10</price><tag>a</tag><tag>b</tag></book>`, http.StatusOK, "Dune 10 [a b]"},
+		{"form", "application/x-www-form-urlencoded", `title=Dune&price=10&tag=a&tag=b`, http.StatusOK, "Dune 10 [a b]"},
+		{"custom", "text/plain", `Dune:10`, http.StatusOK, "Dune 10 []"},
+		{"json validation", "application/json", `{"title":"Dune"}`, http.StatusBadRequest, `{"error":"invalid price"}`},
+		{"xml validation", "application/xml", `<book><title>Dune</title></book>`, http.StatusBadRequest, `{"error":"invalid price"}`},
+		{"form validation", "application/x-www-form-urlencoded", `title=Dune`, http.StatusBadRequest, `{"error":"invalid price"}`},
+		{"form parsing", "application/x-www-form-urlencoded", `title=Dune&price=abc`, http.StatusBadRequest,
+			`{"error":"error parsing payload: price: expected int, got abc","details":{"field":"price","expected":"int","actual":"abc"}}`},
+		{"unsupported", "application/msgpack", `...`, http.StatusUnsupportedMediaType, `{"error":"unsupported media type"}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/", strings.NewReader(c.body))
+			if c.contentType != "" {
+				r.Header.Set("Content-Type", c.contentType)
+			}
+			handler.ServeHTTP(w, r)
+			require.Equal(t, c.expectedCode, w.Code)
+			require.Equal(t, c.expectedBody, w.Body.String())
+		})
+	}
+}
+
+type strictPayload struct {
+	Title string `json:"title"`
+	Extra any    `json:"extra"`
+}
+
+func TestPayload_Strict(t *testing.T) {
+	builder := goergohandler.New()
+	payload := goergohandler.Payload[strictPayload]().
+		WithMaxBodySize(64).
+		DisallowUnknownFields().
+		DisallowTrailingData().
+		UseNumber().
+		Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%T", payload.Get(r).Extra)
+	})
+
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+		resp   string
+	}{
+		{"ok", `{"title":"book","extra":1}`, http.StatusOK, "json.Number"},
+		{"trailing whitespace", `{"title":"book"}` + "\n", http.StatusOK, "<nil>"},
+		{"too large", `{"title":"` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge, `{"error":"payload is too large"}`},
+		{"unknown field", `{"title":"book","price":1}`, http.StatusBadRequest, `{"error":"unknown field \"price\""}`},
+		{"unknown field before type error", `{"price":1,"title":5}`, http.StatusBadRequest,
+			`{"error":"error parsing payload: title: expected string, got number","details":{"field":"title","expected":"string","actual":"number","offset":20,"line":1,"column":20}}`},
+		{"trailing data", `{"title":"book"} {}`, http.StatusBadRequest, `{"error":"unexpected data after payload"}`},
+		{"trailing garbage", `{"title":"book"}garbage`, http.StatusBadRequest, `{"error":"unexpected data after payload"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body)))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+}
+
+func TestPayload_StrictErrors(t *testing.T) {
+	payload := goergohandler.Payload[strictPayload]().DisallowUnknownFields().Attach(goergohandler.New())
+
+	_, err := payload.ParseRequest(t.Context(), httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"price":1}`)))
+	require.ErrorIs(t, err, goergohandler.ErrPayloadUnknownField)
+	require.NotErrorIs(t, err, goergohandler.ErrPayloadParsing)
+}
+
+type unknownFieldBase struct {
+	ID int `json:"id"`
+}
+
+type unknownFieldPayload struct {
+	unknownFieldBase
+	Title   string            `json:"title"`
+	Author  decodeErrorAuthor `json:"author"`
+	Authors []decodeErrorAuthor
+	Meta    map[string]decodeErrorAuthor `json:"meta"`
+}
+
+func TestPayload_UnknownFieldPath(t *testing.T) {
+	payload := goergohandler.Payload[unknownFieldPayload]().DisallowUnknownFields().Attach(goergohandler.New())
+
+	testCases := []struct {
+		body string
+		err  string
+	}{
+		{`{"id":1,"TITLE":"book","author":{"name":"a"},"authors":[{"name":"b"}]}`, ""},
+		{`{"title":"book","price":1}`, `unknown field "price"`},
+		{`{"author":{"name":"a","nick":"b"}}`, `unknown field "author.nick"`},
+		{`{"Authors":[{"name":"a"},{"nick":"b"}]}`, `unknown field "Authors[1].nick"`},
+		{`{"meta":{"editor":{"nick":"b"}}}`, `unknown field "meta.editor.nick"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.body, func(t *testing.T) {
+			_, err := payload.ParseRequest(t.Context(), httptest.NewRecorder(),
+				httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body)))
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, goergohandler.ErrPayloadUnknownField)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+type decodeErrorAuthor struct {
+	Name string `json:"name"`
+}
+
+type decodeErrorPayload struct {
+	Price  int               `json:"price"`
+	Author decodeErrorAuthor `json:"author"`
+}
+
+func TestPayload_DecodeError(t *testing.T) {
+	builder := goergohandler.New()
+	payload := goergohandler.Payload[decodeErrorPayload]().Attach(builder)
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := []struct {
+		name string
+		body string
+		resp string
+	}{
+		{
+			"type error",
+			`{"price":"x"}`,
+			`{"error":"error parsing payload: price: expected int, got string","details":{"field":"price","expected":"int","actual":"string","offset":12,"line":1,"column":12}}`,
+		},
+		{
+			"nested type error",
+			"{\n  \"author\": {\"name\": 5}}",
+			`{"error":"error parsing payload: author.name: expected string, got number","details":{"field":"author.name","expected":"string","actual":"number","offset":24,"line":2,"column":22}}`,
+		},
+		{
+			"syntax error",
+			"{\n  \"price\": x}",
+			`{"error":"error parsing payload: invalid character 'x' looking for beginning of value at line 2, column 12","details":{"offset":14,"line":2,"column":12}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body)))
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+
+	_, err := payload.ParseRequest(t.Context(), httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"price":true}`)))
+	require.ErrorIs(t, err, goergohandler.ErrPayloadParsing)
+	var decodeErr *goergohandler.PayloadDecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	require.Equal(t, "price", decodeErr.Field)
+	require.Equal(t, "bool", decodeErr.Actual)
+}
+
+type formOnlyPayload struct {
+	Title string   `form:"title" default:"untitled"`
+	Tags  []string `form:"tag"`
+}
+
+// The query extensions of QueryStruct are not applied to the forms.
+func TestPayload_FormWithoutQueryExtensions(t *testing.T) {
+	builder := goergohandler.New()
+	payload := goergohandler.Payload[formOnlyPayload]().Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		pl := payload.Get(r)
+		fmt.Fprintf(w, "%q %v", pl.Title, pl.Tags)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`tag[]=a&tag=b`))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `"" [b]`, w.Body.String())
+}
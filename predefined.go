@@ -2,86 +2,432 @@ package goergohandler
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"math"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
-func parseBool(ctx context.Context, v string) (bool, error) {
-	b, err := strconv.ParseBool(v)
-	if err != nil {
-		return false, fmt.Errorf("invalid bool value: %s", v)
+var (
+	ErrInvalidParamValue = errors.New("invalid param value")
+)
+
+// ParamValueError is returned by the predefined parsers when the value cannot be parsed:
+// "limit: invalid int value: abc". It matches ErrInvalidParamValue with errors.Is.
+type ParamValueError struct {
+	Name  string
+	Type  string
+	Value string
+	// Allowed are the allowed values of the enum params.
+	Allowed []string
+}
+
+func (e *ParamValueError) Error() string {
+	return e.Name + ": " + e.valueError()
+}
+
+// valueError is the message without the param name.
+func (e *ParamValueError) valueError() string {
+	msg := fmt.Sprintf("invalid %s value: %s", e.Type, e.Value)
+	if len(e.Allowed) > 0 {
+		msg += ", allowed: " + strings.Join(e.Allowed, ", ")
+	}
+	return msg
+}
+
+func (e *ParamValueError) Is(target error) bool {
+	return target == ErrInvalidParamValue
+}
+
+// predefinedParser turns the parse function into a parser returning ParamValueError on failure.
+func predefinedParser[T any](name, typeName string, parse func(v string) (T, error)) func(ctx context.Context, v string) (T, error) {
+	return func(ctx context.Context, v string) (T, error) {
+		t, err := parse(v)
+		if err != nil {
+			var zero T
+			return zero, &ParamValueError{Name: name, Type: typeName, Value: v}
+		}
+		return t, nil
 	}
-	return b, nil
 }
 
-func parseInt(ctx context.Context, v string) (int, error) {
-	i, err := strconv.Atoi(v)
+func parseString(ctx context.Context, v string) (string, error) {
+	return v, nil
+}
+
+func parseBool(v string) (bool, error) {
+	return strconv.ParseBool(v)
+}
+
+func parseInt(v string) (int, error) {
+	return strconv.Atoi(v)
+}
+
+func parseInt64(v string) (int64, error) {
+	return strconv.ParseInt(v, 10, 64)
+}
+
+func parseUInt64(v string) (uint64, error) {
+	return strconv.ParseUint(v, 10, 64)
+}
+
+// parseFloat64 rejects NaN and infinities.
+func parseFloat64(v string) (float64, error) {
+	f, err := strconv.ParseFloat(v, 64)
 	if err != nil {
-		return 0, fmt.Errorf("invalid int value: %s", v)
+		return 0, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, errors.New("not a finite number")
+	}
+	return f, nil
+}
+
+func parseDuration(v string) (time.Duration, error) {
+	return time.ParseDuration(v)
+}
+
+// timeParser parses the time with the first matching layout.
+// Without layouts RFC3339 and the date-only 2006-01-02 are accepted.
+func timeParser(layouts []string) func(v string) (time.Time, error) {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339, time.DateOnly}
+	}
+	return func(v string) (time.Time, error) {
+		var err error
+		for _, layout := range layouts {
+			var t time.Time
+			if t, err = time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, err
 	}
-	return i, nil
 }
 
-func parseInt64(ctx context.Context, v string) (int64, error) {
-	i, err := strconv.ParseInt(v, 10, 64)
+// parseEmail accepts the bare address: user@example.com, not "User <user@example.com>".
+func parseEmail(v string) (string, error) {
+	addr, err := mail.ParseAddress(v)
 	if err != nil {
-		return 0, fmt.Errorf("invalid int64 value: %s", v)
+		return "", err
+	}
+	if addr.Name != "" || addr.Address != v {
+		return "", errors.New("not a bare address")
 	}
-	return i, nil
+	return v, nil
 }
 
-func parseUInt64(ctx context.Context, v string) (uint64, error) {
-	i, err := strconv.ParseUint(v, 10, 64)
+// parseURL accepts the absolute urls only.
+func parseURL(v string) (*url.URL, error) {
+	u, err := url.Parse(v)
 	if err != nil {
-		return 0, fmt.Errorf("invalid uint64 value: %s", v)
+		return nil, err
 	}
-	return i, nil
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("not an absolute url")
+	}
+	return u, nil
 }
 
+// parseBase64 accepts the standard and the url safe alphabets, padded or not.
+func parseBase64(v string) ([]byte, error) {
+	var err error
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		var b []byte
+		if b, err = enc.DecodeString(v); err == nil {
+			return b, nil
+		}
+	}
+	return nil, err
+}
+
+// The predefined parsers for the query, router params and headers. The parse errors are ParamValueError.
+// QueryParamTime and others accept RFC3339 and 2006-01-02 unless the layouts are passed.
+// QueryParamEmail accepts the bare address, QueryParamURL accepts the absolute urls,
+// QueryParamBase64 accepts the standard and the url safe alphabets.
+
 var QueryParamBool = func(name string) *QueryParamType[bool] {
-	return QueryParam(name, parseBool)
+	return QueryParam(name, predefinedParser(name, "bool", parseBool))
 }
 
 var QueryParamBoolMaybe = func(name string) *QueryParamMaybeType[bool] {
-	return QueryParamMaybe(name, parseBool)
+	return QueryParamMaybe(name, predefinedParser(name, "bool", parseBool))
+}
+
+var RouterParamBool = func(name string) *RouterParamType[bool] {
+	return RouterParam(name, predefinedParser(name, "bool", parseBool))
+}
+
+var HeaderBool = func(name string) *HeaderType[bool] {
+	return Header(name, predefinedParser(name, "bool", parseBool))
+}
+
+var HeaderBoolMaybe = func(name string) *HeaderMaybeType[bool] {
+	return HeaderMaybe(name, predefinedParser(name, "bool", parseBool))
 }
 
 var QueryParamInt = func(name string) *QueryParamType[int] {
-	return QueryParam(name, parseInt)
+	return QueryParam(name, predefinedParser(name, "int", parseInt))
 }
 
 var QueryParamIntMaybe = func(name string) *QueryParamMaybeType[int] {
-	return QueryParamMaybe(name, parseInt)
+	return QueryParamMaybe(name, predefinedParser(name, "int", parseInt))
+}
+
+var RouterParamInt = func(name string) *RouterParamType[int] {
+	return RouterParam(name, predefinedParser(name, "int", parseInt))
+}
+
+var HeaderInt = func(name string) *HeaderType[int] {
+	return Header(name, predefinedParser(name, "int", parseInt))
+}
+
+var HeaderIntMaybe = func(name string) *HeaderMaybeType[int] {
+	return HeaderMaybe(name, predefinedParser(name, "int", parseInt))
 }
 
 var QueryParamInt64 = func(name string) *QueryParamType[int64] {
-	return QueryParam(name, parseInt64)
+	return QueryParam(name, predefinedParser(name, "int64", parseInt64))
 }
 
 var QueryParamInt64Maybe = func(name string) *QueryParamMaybeType[int64] {
-	return QueryParamMaybe(name, parseInt64)
+	return QueryParamMaybe(name, predefinedParser(name, "int64", parseInt64))
+}
+
+var RouterParamInt64 = func(name string) *RouterParamType[int64] {
+	return RouterParam(name, predefinedParser(name, "int64", parseInt64))
+}
+
+var HeaderInt64 = func(name string) *HeaderType[int64] {
+	return Header(name, predefinedParser(name, "int64", parseInt64))
+}
+
+var HeaderInt64Maybe = func(name string) *HeaderMaybeType[int64] {
+	return HeaderMaybe(name, predefinedParser(name, "int64", parseInt64))
 }
 
 var QueryParamUInt64 = func(name string) *QueryParamType[uint64] {
-	return QueryParam(name, parseUInt64)
+	return QueryParam(name, predefinedParser(name, "uint64", parseUInt64))
 }
 
 var QueryParamUInt64Maybe = func(name string) *QueryParamMaybeType[uint64] {
-	return QueryParamMaybe(name, parseUInt64)
+	return QueryParamMaybe(name, predefinedParser(name, "uint64", parseUInt64))
 }
 
-var RouterParamInt64 = func(name string) *RouterParamType[int64] {
-	return RouterParam(name, parseInt64)
+var RouterParamUInt64 = func(name string) *RouterParamType[uint64] {
+	return RouterParam(name, predefinedParser(name, "uint64", parseUInt64))
+}
+
+var HeaderUInt64 = func(name string) *HeaderType[uint64] {
+	return Header(name, predefinedParser(name, "uint64", parseUInt64))
+}
+
+var HeaderUInt64Maybe = func(name string) *HeaderMaybeType[uint64] {
+	return HeaderMaybe(name, predefinedParser(name, "uint64", parseUInt64))
+}
+
+var QueryParamFloat64 = func(name string) *QueryParamType[float64] {
+	return QueryParam(name, predefinedParser(name, "float64", parseFloat64))
+}
+
+var QueryParamFloat64Maybe = func(name string) *QueryParamMaybeType[float64] {
+	return QueryParamMaybe(name, predefinedParser(name, "float64", parseFloat64))
+}
+
+var RouterParamFloat64 = func(name string) *RouterParamType[float64] {
+	return RouterParam(name, predefinedParser(name, "float64", parseFloat64))
+}
+
+var HeaderFloat64 = func(name string) *HeaderType[float64] {
+	return Header(name, predefinedParser(name, "float64", parseFloat64))
+}
+
+var HeaderFloat64Maybe = func(name string) *HeaderMaybeType[float64] {
+	return HeaderMaybe(name, predefinedParser(name, "float64", parseFloat64))
+}
+
+var QueryParamTime = func(name string, layouts ...string) *QueryParamType[time.Time] {
+	return QueryParam(name, predefinedParser(name, "time", timeParser(layouts)))
+}
+
+var QueryParamTimeMaybe = func(name string, layouts ...string) *QueryParamMaybeType[time.Time] {
+	return QueryParamMaybe(name, predefinedParser(name, "time", timeParser(layouts)))
+}
+
+var RouterParamTime = func(name string, layouts ...string) *RouterParamType[time.Time] {
+	return RouterParam(name, predefinedParser(name, "time", timeParser(layouts)))
+}
+
+var HeaderTime = func(name string, layouts ...string) *HeaderType[time.Time] {
+	return Header(name, predefinedParser(name, "time", timeParser(layouts)))
+}
+
+var HeaderTimeMaybe = func(name string, layouts ...string) *HeaderMaybeType[time.Time] {
+	return HeaderMaybe(name, predefinedParser(name, "time", timeParser(layouts)))
+}
+
+var QueryParamDuration = func(name string) *QueryParamType[time.Duration] {
+	return QueryParam(name, predefinedParser(name, "duration", parseDuration))
+}
+
+var QueryParamDurationMaybe = func(name string) *QueryParamMaybeType[time.Duration] {
+	return QueryParamMaybe(name, predefinedParser(name, "duration", parseDuration))
+}
+
+var RouterParamDuration = func(name string) *RouterParamType[time.Duration] {
+	return RouterParam(name, predefinedParser(name, "duration", parseDuration))
+}
+
+var HeaderDuration = func(name string) *HeaderType[time.Duration] {
+	return Header(name, predefinedParser(name, "duration", parseDuration))
+}
+
+var HeaderDurationMaybe = func(name string) *HeaderMaybeType[time.Duration] {
+	return HeaderMaybe(name, predefinedParser(name, "duration", parseDuration))
+}
+
+var QueryParamUUID = func(name string) *QueryParamType[UUID] {
+	return QueryParam(name, predefinedParser(name, "uuid", ParseUUID))
+}
+
+var QueryParamUUIDMaybe = func(name string) *QueryParamMaybeType[UUID] {
+	return QueryParamMaybe(name, predefinedParser(name, "uuid", ParseUUID))
+}
+
+var RouterParamUUID = func(name string) *RouterParamType[UUID] {
+	return RouterParam(name, predefinedParser(name, "uuid", ParseUUID))
+}
+
+var HeaderUUID = func(name string) *HeaderType[UUID] {
+	return Header(name, predefinedParser(name, "uuid", ParseUUID))
+}
+
+var HeaderUUIDMaybe = func(name string) *HeaderMaybeType[UUID] {
+	return HeaderMaybe(name, predefinedParser(name, "uuid", ParseUUID))
+}
+
+var QueryParamIP = func(name string) *QueryParamType[netip.Addr] {
+	return QueryParam(name, predefinedParser(name, "ip", netip.ParseAddr))
+}
+
+var QueryParamIPMaybe = func(name string) *QueryParamMaybeType[netip.Addr] {
+	return QueryParamMaybe(name, predefinedParser(name, "ip", netip.ParseAddr))
+}
+
+var RouterParamIP = func(name string) *RouterParamType[netip.Addr] {
+	return RouterParam(name, predefinedParser(name, "ip", netip.ParseAddr))
+}
+
+var HeaderIP = func(name string) *HeaderType[netip.Addr] {
+	return Header(name, predefinedParser(name, "ip", netip.ParseAddr))
+}
+
+var HeaderIPMaybe = func(name string) *HeaderMaybeType[netip.Addr] {
+	return HeaderMaybe(name, predefinedParser(name, "ip", netip.ParseAddr))
+}
+
+var QueryParamCIDR = func(name string) *QueryParamType[netip.Prefix] {
+	return QueryParam(name, predefinedParser(name, "cidr", netip.ParsePrefix))
+}
+
+var QueryParamCIDRMaybe = func(name string) *QueryParamMaybeType[netip.Prefix] {
+	return QueryParamMaybe(name, predefinedParser(name, "cidr", netip.ParsePrefix))
+}
+
+var RouterParamCIDR = func(name string) *RouterParamType[netip.Prefix] {
+	return RouterParam(name, predefinedParser(name, "cidr", netip.ParsePrefix))
+}
+
+var HeaderCIDR = func(name string) *HeaderType[netip.Prefix] {
+	return Header(name, predefinedParser(name, "cidr", netip.ParsePrefix))
+}
+
+var HeaderCIDRMaybe = func(name string) *HeaderMaybeType[netip.Prefix] {
+	return HeaderMaybe(name, predefinedParser(name, "cidr", netip.ParsePrefix))
+}
+
+var QueryParamEmail = func(name string) *QueryParamType[string] {
+	return QueryParam(name, predefinedParser(name, "email", parseEmail))
+}
+
+var QueryParamEmailMaybe = func(name string) *QueryParamMaybeType[string] {
+	return QueryParamMaybe(name, predefinedParser(name, "email", parseEmail))
+}
+
+var RouterParamEmail = func(name string) *RouterParamType[string] {
+	return RouterParam(name, predefinedParser(name, "email", parseEmail))
+}
+
+var HeaderEmail = func(name string) *HeaderType[string] {
+	return Header(name, predefinedParser(name, "email", parseEmail))
+}
+
+var HeaderEmailMaybe = func(name string) *HeaderMaybeType[string] {
+	return HeaderMaybe(name, predefinedParser(name, "email", parseEmail))
+}
+
+var QueryParamURL = func(name string) *QueryParamType[*url.URL] {
+	return QueryParam(name, predefinedParser(name, "url", parseURL))
+}
+
+var QueryParamURLMaybe = func(name string) *QueryParamMaybeType[*url.URL] {
+	return QueryParamMaybe(name, predefinedParser(name, "url", parseURL))
+}
+
+var RouterParamURL = func(name string) *RouterParamType[*url.URL] {
+	return RouterParam(name, predefinedParser(name, "url", parseURL))
+}
+
+var HeaderURL = func(name string) *HeaderType[*url.URL] {
+	return Header(name, predefinedParser(name, "url", parseURL))
+}
+
+var HeaderURLMaybe = func(name string) *HeaderMaybeType[*url.URL] {
+	return HeaderMaybe(name, predefinedParser(name, "url", parseURL))
+}
+
+var QueryParamBase64 = func(name string) *QueryParamType[[]byte] {
+	return QueryParam(name, predefinedParser(name, "base64", parseBase64))
+}
+
+var QueryParamBase64Maybe = func(name string) *QueryParamMaybeType[[]byte] {
+	return QueryParamMaybe(name, predefinedParser(name, "base64", parseBase64))
+}
+
+var RouterParamBase64 = func(name string) *RouterParamType[[]byte] {
+	return RouterParam(name, predefinedParser(name, "base64", parseBase64))
+}
+
+var HeaderBase64 = func(name string) *HeaderType[[]byte] {
+	return Header(name, predefinedParser(name, "base64", parseBase64))
+}
+
+var HeaderBase64Maybe = func(name string) *HeaderMaybeType[[]byte] {
+	return HeaderMaybe(name, predefinedParser(name, "base64", parseBase64))
 }
 
 var QueryParamString = func(name string) *QueryParamType[string] {
-	return QueryParam(name, func(ctx context.Context, v string) (string, error) {
-		return v, nil
-	})
+	return QueryParam(name, parseString)
 }
 
 var QueryParamStringMaybe = func(name string) *QueryParamMaybeType[string] {
-	return QueryParamMaybe(name, func(ctx context.Context, v string) (string, error) {
-		return v, nil
-	})
+	return QueryParamMaybe(name, parseString)
+}
+
+var RouterParamString = func(name string) *RouterParamType[string] {
+	return RouterParam(name, parseString)
+}
+
+var HeaderString = func(name string) *HeaderType[string] {
+	return Header(name, parseString)
+}
+
+var HeaderStringMaybe = func(name string) *HeaderMaybeType[string] {
+	return HeaderMaybe(name, parseString)
 }
@@ -0,0 +1,153 @@
+package goergohandler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/nktknshn/go-ergo-handler/adapters/stdlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredefined_Query(t *testing.T) {
+	builder := geh.New()
+	price := geh.QueryParamFloat64Maybe("price").Attach(builder)
+	since := geh.QueryParamTimeMaybe("since").Attach(builder)
+	month := geh.QueryParamTimeMaybe("month", "2006-01").Attach(builder)
+	ttl := geh.QueryParamDurationMaybe("ttl").Attach(builder)
+	id := geh.QueryParamUUIDMaybe("id").Attach(builder)
+	ip := geh.QueryParamIPMaybe("ip").Attach(builder)
+	cidr := geh.QueryParamCIDRMaybe("cidr").Attach(builder)
+	email := geh.QueryParamEmailMaybe("email").Attach(builder)
+	link := geh.QueryParamURLMaybe("url").Attach(builder)
+	data := geh.QueryParamBase64Maybe("data").Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		u := link.GetDefault(r, &url.URL{})
+		fmt.Fprintf(w, "%v %s %s %v %s %v %v %s %s %q",
+			price.GetDefault(r, 0),
+			since.GetDefault(r, time.Time{}).Format(time.RFC3339),
+			month.GetDefault(r, time.Time{}).Format(time.DateOnly),
+			ttl.GetDefault(r, 0),
+			id.GetDefault(r, geh.UUID{}),
+			ip.GetDefault(r, netip.Addr{}),
+			cidr.GetDefault(r, netip.Prefix{}),
+			email.GetDefault(r, ""),
+			u.Host,
+			data.GetDefault(r, nil),
+		)
+	})
+
+	testCases := []struct {
+		query  string
+		status int
+		resp   string
+	}{
+		{
+			"", http.StatusOK,
+			`0 0001-01-01T00:00:00Z 0001-01-01 0s 00000000-0000-0000-0000-000000000000 invalid IP invalid Prefix   ""`,
+		},
+		{
+			"price=9.99&since=2024-05-01&month=2024-05&ttl=1h30m&id=6BA7B810-9DAD-11D1-80B4-00C04FD430C8" +
+				"&ip=::1&cidr=10.0.0.0/8&email=rob@example.com&url=https://example.com/books&data=aGk_",
+			http.StatusOK,
+			`9.99 2024-05-01T00:00:00Z 2024-05-01 1h30m0s 6ba7b810-9dad-11d1-80b4-00c04fd430c8 ::1 10.0.0.0/8 rob@example.com example.com "hi?"`,
+		},
+		{"since=2024-05-01T10:00:00%2B02:00", http.StatusOK,
+			`0 2024-05-01T10:00:00+02:00 0001-01-01 0s 00000000-0000-0000-0000-000000000000 invalid IP invalid Prefix   ""`},
+		{"price=NaN", http.StatusBadRequest, `{"error":"price: invalid float64 value: NaN"}`},
+		{"since=yesterday", http.StatusBadRequest, `{"error":"since: invalid time value: yesterday"}`},
+		{"month=2024-05-01", http.StatusBadRequest, `{"error":"month: invalid time value: 2024-05-01"}`},
+		{"ttl=forever", http.StatusBadRequest, `{"error":"ttl: invalid duration value: forever"}`},
+		{"id=6ba7b810-9dad-11d1-80b4-00c04fd430", http.StatusBadRequest, `{"error":"id: invalid uuid value: 6ba7b810-9dad-11d1-80b4-00c04fd430"}`},
+		{"ip=1.2.3", http.StatusBadRequest, `{"error":"ip: invalid ip value: 1.2.3"}`},
+		{"cidr=10.0.0.0", http.StatusBadRequest, `{"error":"cidr: invalid cidr value: 10.0.0.0"}`},
+		{"email=+rob@example.com", http.StatusBadRequest, `{"error":"email: invalid email value:  rob@example.com"}`},
+		{"url=/books", http.StatusBadRequest, `{"error":"url: invalid url value: /books"}`},
+		{"data=!!", http.StatusBadRequest, `{"error":"data: invalid base64 value: !!"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?"+tc.query, nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+}
+
+func TestPredefined_RouterAndHeader(t *testing.T) {
+	builder := geh.New().WithVarsGetter(stdlib.New())
+	id := geh.RouterParamUUID("id").Attach(builder)
+	at := geh.HeaderTime("X-At").Attach(builder)
+	limit := geh.HeaderIntMaybe("X-Limit").Attach(builder)
+
+	mux := http.NewServeMux()
+	mux.Handle("/books/{id}", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %d", id.Get(r), at.Get(r).Format(time.DateOnly), limit.GetDefault(r, 10))
+	}))
+
+	testCases := []struct {
+		path    string
+		headers map[string]string
+		status  int
+		resp    string
+	}{
+		{"/books/6ba7b810-9dad-11d1-80b4-00c04fd430c8", map[string]string{"X-At": "2024-05-01"}, http.StatusOK,
+			"6ba7b810-9dad-11d1-80b4-00c04fd430c8 2024-05-01 10"},
+		{"/books/1", map[string]string{"X-At": "2024-05-01"}, http.StatusBadRequest, `{"error":"id: invalid uuid value: 1"}`},
+		{"/books/6ba7b810-9dad-11d1-80b4-00c04fd430c8", map[string]string{"X-At": "2024-05-01", "X-Limit": "ten"}, http.StatusBadRequest,
+			`{"error":"X-Limit: invalid int value: ten"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+}
+
+func TestPredefined_ParamValueError(t *testing.T) {
+	builder := geh.New().WithCollectErrors()
+	geh.QueryParamInt("limit").Attach(builder)
+
+	var err error
+	handler := builder.WithHandlerErrorFunc(func(_ context.Context, w http.ResponseWriter, r *http.Request, e error) {
+		err = e
+	}).BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?limit=abc", nil))
+
+	require.ErrorIs(t, err, geh.ErrInvalidParamValue)
+	var ve *geh.ParamValueError
+	require.True(t, errors.As(err, &ve))
+	require.Equal(t, geh.ParamValueError{Name: "limit", Type: "int", Value: "abc"}, *ve)
+	// the name is not repeated in the collected errors
+	require.Equal(t, "limit: invalid int value: abc", err.Error())
+}
+
+func TestParseUUID(t *testing.T) {
+	u, err := geh.ParseUUID("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	require.NoError(t, err)
+	require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", u.String())
+	require.False(t, u.IsZero())
+
+	for _, s := range []string{"", "6ba7b8109dad11d180b400c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430cg", "6ba7b810-9dad-11d1-80b4_00c04fd430c8"} {
+		_, err := geh.ParseUUID(s)
+		require.ErrorIs(t, err, geh.ErrInvalidUUID, s)
+	}
+}
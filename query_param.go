@@ -21,8 +21,6 @@ func newQueryParamMissingError(paramName string) error {
 	return fmt.Errorf("%w: %s", ErrQueryParamMissing, paramName)
 }
 
-type queryParamKeyType string
-
 type QueryParamParserFunc[T any] func(ctx context.Context, v string) (T, error)
 
 type QueryParamType[T any] struct {
@@ -77,7 +75,21 @@ func (p *AttachedQueryParam[T]) ParseRequest(ctx context.Context, w http.Respons
 	if err != nil {
 		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrQueryParamValidation)
 	}
-	return context.WithValue(ctx, queryParamKeyType(p.qp.Name), v), nil
+	return context.WithValue(ctx, p, v), nil
+}
+
+func (p *AttachedQueryParam[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location: ParamLocationQuery,
+		Name:     p.qp.Name,
+		Type:     typeOf[T](),
+		Required: true,
+		ErrorStatuses: uniqueStatuses(
+			defaultHttpStatusCodeErrQueryParamMissing,
+			defaultHttpStatusCodeErrQueryParamParsing,
+			defaultHttpStatusCodeErrQueryParamValidation,
+		),
+	}}
 }
 
 func (p *AttachedQueryParam[T]) Get(r *http.Request) T {
@@ -85,5 +97,5 @@ func (p *AttachedQueryParam[T]) Get(r *http.Request) T {
 }
 
 func (p *AttachedQueryParam[T]) GetContext(ctx context.Context) T {
-	return GetFromContext[T](ctx, queryParamKeyType(p.qp.Name))
+	return GetFromContext[T](ctx, p)
 }
@@ -47,7 +47,20 @@ func (p *AttachedQueryParamMaybe[T]) ParseRequest(ctx context.Context, w http.Re
 	if err != nil {
 		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrQueryParamValidation)
 	}
-	return context.WithValue(ctx, queryParamKeyType(p.qp.Name), v), nil
+	return context.WithValue(ctx, p, v), nil
+}
+
+func (p *AttachedQueryParamMaybe[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location: ParamLocationQuery,
+		Name:     p.qp.Name,
+		Type:     typeOf[T](),
+		Required: false,
+		ErrorStatuses: uniqueStatuses(
+			defaultHttpStatusCodeErrQueryParamParsing,
+			defaultHttpStatusCodeErrQueryParamValidation,
+		),
+	}}
 }
 
 func (p *AttachedQueryParamMaybe[T]) GetMaybe(r *http.Request) (*T, bool) {
@@ -67,5 +80,5 @@ func (p *AttachedQueryParamMaybe[T]) GetContextDefault(ctx context.Context, defa
 }
 
 func (p *AttachedQueryParamMaybe[T]) GetContextMaybe(ctx context.Context) (*T, bool) {
-	return GetFromContextMaybe[T](ctx, queryParamKeyType(p.qp.Name))
+	return GetFromContextMaybe[T](ctx, p)
 }
@@ -0,0 +1,277 @@
+package goergohandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultHttpStatusCodeErrQueryParamItemCount = http.StatusBadRequest
+
+	defaultQueryParamSliceDelimiter = ","
+)
+
+var (
+	ErrQueryParamTooFewItems  = errors.New("too few items in query param")
+	ErrQueryParamTooManyItems = errors.New("too many items in query param")
+)
+
+// querySliceOptions are shared by QueryParamSlice and QueryParamSliceMaybe.
+type querySliceOptions struct {
+	// empty means the values are not split
+	delimiter string
+	// zero means no limit
+	minItems, maxItems int
+}
+
+// parseQuerySlice parses the values of the query param. The values are taken from the repeated
+// name=a&name=b and name[]=a&name[]=b params and split by the delimiter. Empty items are skipped
+// but counted in the indexes reported by the errors, so ?tag=2,,x fails at tag[2].
+// The returned bool is false if the param is missing or has no items, e.g. ?tag=.
+// Parse errors are ParamValueError named by the item: tag[2].
+func parseQuerySlice[T any](ctx context.Context, r *http.Request, name string, parser QueryParamParserFunc[T], opts querySliceOptions) ([]T, bool, error) {
+	query := r.URL.Query()
+	type item struct {
+		index int
+		value string
+	}
+	var items []item
+	index := 0
+	for _, v := range slices.Concat(query[name], query[name+"[]"]) {
+		parts := []string{v}
+		if opts.delimiter != "" {
+			parts = strings.Split(v, opts.delimiter)
+		}
+		for _, part := range parts {
+			if part != "" {
+				items = append(items, item{index, part})
+			}
+			index++
+		}
+	}
+	if len(items) == 0 {
+		return nil, false, nil
+	}
+
+	if opts.minItems > 0 && len(items) < opts.minItems {
+		return nil, true, WrapWithStatusCode(
+			fmt.Errorf("%w: %s: got %d, at least %d required", ErrQueryParamTooFewItems, name, len(items), opts.minItems),
+			defaultHttpStatusCodeErrQueryParamItemCount,
+		)
+	}
+	if opts.maxItems > 0 && len(items) > opts.maxItems {
+		return nil, true, WrapWithStatusCode(
+			fmt.Errorf("%w: %s: got %d, at most %d allowed", ErrQueryParamTooManyItems, name, len(items), opts.maxItems),
+			defaultHttpStatusCodeErrQueryParamItemCount,
+		)
+	}
+
+	values := make([]T, 0, len(items))
+	for _, item := range items {
+		itemName := fmt.Sprintf("%s[%d]", name, item.index)
+		v, err := parser(ctx, item.value)
+		if err != nil {
+			return nil, true, WrapWithStatusCode(sliceItemValueError(itemName, err), defaultHttpStatusCodeErrQueryParamParsing)
+		}
+		err = ValidateWithValidation(v)
+		if err != nil {
+			return nil, true, WrapWithStatusCode(fmt.Errorf("%s: %w", itemName, err), defaultHttpStatusCodeErrQueryParamValidation)
+		}
+		values = append(values, v)
+	}
+	return values, true, nil
+}
+
+// sliceItemValueError names the error after the item. The ParamValueError of the predefined parsers
+// is returned with the item name, other errors are wrapped.
+func sliceItemValueError(name string, err error) error {
+	var ve *ParamValueError
+	if errors.As(err, &ve) {
+		itemErr := *ve
+		itemErr.Name = name
+		return &itemErr
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+func (o querySliceOptions) errorStatuses() []int {
+	return []int{
+		defaultHttpStatusCodeErrQueryParamParsing,
+		defaultHttpStatusCodeErrQueryParamValidation,
+		defaultHttpStatusCodeErrQueryParamItemCount,
+	}
+}
+
+type QueryParamSliceType[T any] struct {
+	Name       string
+	Parser     QueryParamParserFunc[T]
+	ErrMissing error
+	opts       querySliceOptions
+}
+
+// QueryParamSlice is a parser that parses a required query param holding a list of values.
+// All of ?tag=a&tag=b, ?tag=a,b and ?tag[]=a&tag[]=b give [a b].
+// The parser and WithValidation are applied to every item; the errors report the index of the failing item.
+// If the query param is missing or has no items, e.g. ?tag=, it returns ErrQueryParamMissing.
+func QueryParamSlice[T any](name string, parser QueryParamParserFunc[T]) *QueryParamSliceType[T] {
+	return &QueryParamSliceType[T]{
+		Name:   name,
+		Parser: parser,
+		opts:   querySliceOptions{delimiter: defaultQueryParamSliceDelimiter},
+	}
+}
+
+// WithMissingError sets the error to be returned if the query param is missing.
+func (qp *QueryParamSliceType[T]) WithMissingError(err error) *QueryParamSliceType[T] {
+	qp.ErrMissing = err
+	return qp
+}
+
+// WithDelimiter sets the delimiter the values are split by. Default is ",". Empty delimiter disables splitting.
+func (qp *QueryParamSliceType[T]) WithDelimiter(delimiter string) *QueryParamSliceType[T] {
+	qp.opts.delimiter = delimiter
+	return qp
+}
+
+// WithMinItems rejects the lists shorter than n with ErrQueryParamTooFewItems.
+func (qp *QueryParamSliceType[T]) WithMinItems(n int) *QueryParamSliceType[T] {
+	qp.opts.minItems = n
+	return qp
+}
+
+// WithMaxItems rejects the lists longer than n with ErrQueryParamTooManyItems.
+func (qp *QueryParamSliceType[T]) WithMaxItems(n int) *QueryParamSliceType[T] {
+	qp.opts.maxItems = n
+	return qp
+}
+
+func (qp *QueryParamSliceType[T]) Attach(b ParserAdder) *AttachedQueryParamSlice[T] {
+	a := &AttachedQueryParamSlice[T]{qp}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedQueryParamSlice[T any] struct {
+	qp *QueryParamSliceType[T]
+}
+
+func (p *AttachedQueryParamSlice[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	values, ok, err := parseQuerySlice(ctx, r, p.qp.Name, p.qp.Parser, p.qp.opts)
+	if err != nil {
+		return ctx, err
+	}
+	if !ok {
+		err := p.qp.ErrMissing
+		if err == nil {
+			err = newQueryParamMissingError(p.qp.Name)
+		}
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrQueryParamMissing)
+	}
+	return context.WithValue(ctx, p, values), nil
+}
+
+func (p *AttachedQueryParamSlice[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location:      ParamLocationQuery,
+		Name:          p.qp.Name,
+		Type:          typeOf[[]T](),
+		Required:      true,
+		ErrorStatuses: uniqueStatuses(append(p.qp.opts.errorStatuses(), defaultHttpStatusCodeErrQueryParamMissing)...),
+	}}
+}
+
+func (p *AttachedQueryParamSlice[T]) Get(r *http.Request) []T {
+	return p.GetContext(r.Context())
+}
+
+func (p *AttachedQueryParamSlice[T]) GetContext(ctx context.Context) []T {
+	return GetFromContext[[]T](ctx, p)
+}
+
+type QueryParamSliceMaybeType[T any] struct {
+	Name   string
+	Parser QueryParamParserFunc[T]
+	opts   querySliceOptions
+}
+
+// QueryParamSliceMaybe is same as QueryParamSlice but it doesn't return an error if the query param is missing.
+func QueryParamSliceMaybe[T any](name string, parser QueryParamParserFunc[T]) *QueryParamSliceMaybeType[T] {
+	return &QueryParamSliceMaybeType[T]{
+		Name:   name,
+		Parser: parser,
+		opts:   querySliceOptions{delimiter: defaultQueryParamSliceDelimiter},
+	}
+}
+
+// WithDelimiter sets the delimiter the values are split by. Default is ",". Empty delimiter disables splitting.
+func (qp *QueryParamSliceMaybeType[T]) WithDelimiter(delimiter string) *QueryParamSliceMaybeType[T] {
+	qp.opts.delimiter = delimiter
+	return qp
+}
+
+// WithMinItems rejects the lists shorter than n with ErrQueryParamTooFewItems.
+func (qp *QueryParamSliceMaybeType[T]) WithMinItems(n int) *QueryParamSliceMaybeType[T] {
+	qp.opts.minItems = n
+	return qp
+}
+
+// WithMaxItems rejects the lists longer than n with ErrQueryParamTooManyItems.
+func (qp *QueryParamSliceMaybeType[T]) WithMaxItems(n int) *QueryParamSliceMaybeType[T] {
+	qp.opts.maxItems = n
+	return qp
+}
+
+func (qp *QueryParamSliceMaybeType[T]) Attach(b ParserAdder) *AttachedQueryParamSliceMaybe[T] {
+	a := &AttachedQueryParamSliceMaybe[T]{qp}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedQueryParamSliceMaybe[T any] struct {
+	qp *QueryParamSliceMaybeType[T]
+}
+
+func (p *AttachedQueryParamSliceMaybe[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	values, ok, err := parseQuerySlice(ctx, r, p.qp.Name, p.qp.Parser, p.qp.opts)
+	if err != nil {
+		return ctx, err
+	}
+	if !ok {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, p, values), nil
+}
+
+func (p *AttachedQueryParamSliceMaybe[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location:      ParamLocationQuery,
+		Name:          p.qp.Name,
+		Type:          typeOf[[]T](),
+		Required:      false,
+		ErrorStatuses: uniqueStatuses(p.qp.opts.errorStatuses()...),
+	}}
+}
+
+func (p *AttachedQueryParamSliceMaybe[T]) GetMaybe(r *http.Request) (*[]T, bool) {
+	return p.GetContextMaybe(r.Context())
+}
+
+func (p *AttachedQueryParamSliceMaybe[T]) GetDefault(r *http.Request, defaultVal []T) []T {
+	return p.GetContextDefault(r.Context(), defaultVal)
+}
+
+func (p *AttachedQueryParamSliceMaybe[T]) GetContextDefault(ctx context.Context, defaultVal []T) []T {
+	v, ok := p.GetContextMaybe(ctx)
+	if !ok {
+		return defaultVal
+	}
+	return *v
+}
+
+func (p *AttachedQueryParamSliceMaybe[T]) GetContextMaybe(ctx context.Context) (*[]T, bool) {
+	return GetFromContextMaybe[[]T](ctx, p)
+}
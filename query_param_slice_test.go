@@ -0,0 +1,94 @@
+package goergohandler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryParamSlice(t *testing.T) {
+	builder := geh.New()
+	ids := geh.QueryParamSlice("ids", geh.IgnoreContext(strconv.Atoi)).WithMaxItems(4).Attach(builder)
+	tags := geh.QueryParamSliceMaybe("tag", func(ctx context.Context, s string) (paramBookIDType, error) {
+		v, err := strconv.Atoi(s)
+		return paramBookIDType(v), err
+	}).WithMinItems(2).Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%v %v", ids.Get(r), tags.GetDefault(r, nil))
+	})
+
+	testCases := []struct {
+		query  string
+		status int
+		resp   string
+	}{
+		{"ids=1&ids=2", http.StatusOK, "[1 2] []"},
+		{"ids=1,2,3", http.StatusOK, "[1 2 3] []"},
+		{"ids[]=1&ids[]=2", http.StatusOK, "[1 2] []"},
+		{"ids=1,2&ids[]=3", http.StatusOK, "[1 2 3] []"},
+		{"ids=", http.StatusBadRequest, `{"error":"required query param is missing: ids"}`},
+		{"ids=,", http.StatusBadRequest, `{"error":"required query param is missing: ids"}`},
+		{"ids=1&tag=", http.StatusOK, "[1] []"},
+		{"ids=1&tag=2,3", http.StatusOK, "[1] [2 3]"},
+		{"", http.StatusBadRequest, `{"error":"required query param is missing: ids"}`},
+		{"ids=1,x,3", http.StatusBadRequest, `{"error":"ids[1]: strconv.Atoi: parsing \"x\": invalid syntax"}`},
+		{"ids=1,,x&ids[]=y", http.StatusBadRequest, `{"error":"ids[2]: strconv.Atoi: parsing \"x\": invalid syntax"}`},
+		{"ids=1,2,3,4,5", http.StatusBadRequest, `{"error":"too many items in query param: ids: got 5, at most 4 allowed"}`},
+		{"ids=1&tag=ab", http.StatusBadRequest, `{"error":"too few items in query param: tag: got 1, at least 2 required"}`},
+		{"ids=1&tag=2,,0", http.StatusBadRequest, `{"error":"tag[2]: invalid book id"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?"+tc.query, nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+}
+
+func TestQueryParamSlice_ParamValueError(t *testing.T) {
+	builder := geh.New().WithCollectErrors()
+	geh.QueryParamSlice("ids", geh.QueryParamInt("ids").Parser).Attach(builder)
+
+	var err error
+	handler := builder.WithHandlerErrorFunc(func(_ context.Context, w http.ResponseWriter, r *http.Request, e error) {
+		err = e
+	}).BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?ids=1,x", nil))
+
+	require.ErrorIs(t, err, geh.ErrInvalidParamValue)
+	var ve *geh.ParamValueError
+	require.True(t, errors.As(err, &ve))
+	require.Equal(t, geh.ParamValueError{Name: "ids[1]", Type: "int", Value: "x"}, *ve)
+}
+
+var errInvalidISBN = errors.New("invalid isbn")
+
+func TestQueryParamSlice_ParserError(t *testing.T) {
+	builder := geh.New()
+	geh.QueryParamSlice("isbn", func(ctx context.Context, s string) (string, error) {
+		if len(s) != 13 {
+			return "", errInvalidISBN
+		}
+		return s, nil
+	}).Attach(builder)
+
+	var err error
+	handler := builder.WithHandlerErrorFunc(func(_ context.Context, w http.ResponseWriter, r *http.Request, e error) {
+		err = e
+	}).BuildHandler(func(w http.ResponseWriter, r *http.Request) {})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?isbn=9780441013593,123", nil))
+
+	require.ErrorIs(t, err, errInvalidISBN)
+	require.EqualError(t, err, "isbn[1]: invalid isbn")
+}
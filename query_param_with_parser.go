@@ -51,7 +51,21 @@ func (p *AttachedQueryParamWithParser[T]) ParseRequest(ctx context.Context, w ht
 	if err != nil {
 		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrQueryParamValidation)
 	}
-	return context.WithValue(ctx, queryParamKeyType(p.qp.Name), v), nil
+	return context.WithValue(ctx, p, v), nil
+}
+
+func (p *AttachedQueryParamWithParser[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location: ParamLocationQuery,
+		Name:     p.qp.Name,
+		Type:     typeOf[T](),
+		Required: true,
+		ErrorStatuses: uniqueStatuses(
+			defaultHttpStatusCodeErrQueryParamMissing,
+			defaultHttpStatusCodeErrQueryParamParsing,
+			defaultHttpStatusCodeErrQueryParamValidation,
+		),
+	}}
 }
 
 func (p *AttachedQueryParamWithParser[T]) Get(r *http.Request) T {
@@ -59,5 +73,5 @@ func (p *AttachedQueryParamWithParser[T]) Get(r *http.Request) T {
 }
 
 func (p *AttachedQueryParamWithParser[T]) GetContext(ctx context.Context) T {
-	return GetFromContext[T](ctx, queryParamKeyType(p.qp.Name))
+	return GetFromContext[T](ctx, p)
 }
@@ -37,7 +37,20 @@ func (a *AttachedQueryParamWithParserMaybe[T]) ParseRequest(ctx context.Context,
 	if err != nil {
 		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrQueryParamValidation)
 	}
-	return context.WithValue(ctx, queryParamKeyType(a.qp.Name), v), nil
+	return context.WithValue(ctx, a, v), nil
+}
+
+func (a *AttachedQueryParamWithParserMaybe[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location: ParamLocationQuery,
+		Name:     a.qp.Name,
+		Type:     typeOf[T](),
+		Required: false,
+		ErrorStatuses: uniqueStatuses(
+			defaultHttpStatusCodeErrQueryParamParsing,
+			defaultHttpStatusCodeErrQueryParamValidation,
+		),
+	}}
 }
 
 func (a *AttachedQueryParamWithParserMaybe[T]) GetMaybe(r *http.Request) (*T, bool) {
@@ -49,7 +62,7 @@ func (a *AttachedQueryParamWithParserMaybe[T]) GetDefault(r *http.Request, defau
 }
 
 func (p *AttachedQueryParamWithParserMaybe[T]) GetContextMaybe(ctx context.Context) (*T, bool) {
-	return GetFromContextMaybe[T](ctx, queryParamKeyType(p.qp.Name))
+	return GetFromContextMaybe[T](ctx, p)
 }
 
 func (a *AttachedQueryParamWithParserMaybe[T]) GetContextDefault(ctx context.Context, defaultVal T) T {
@@ -0,0 +1,97 @@
+package goergohandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+const (
+	defaultHttpStatusCodeErrQueryStructParsing    = http.StatusBadRequest
+	defaultHttpStatusCodeErrQueryStructValidation = http.StatusBadRequest
+
+	queryStructTagName = "query"
+)
+
+type QueryStructType[T any] struct{}
+
+// QueryStruct is a parser that decodes the query params into the struct T.
+// The fields are matched by the query tag: `query:"limit"`, the field name is used if the tag is missing.
+//   - pointer fields are set only if the param is present
+//   - slice fields get the repeated params: ?tag=a&tag=b or ?tag[]=a&tag[]=b
+//   - nested struct fields are decoded from the deep object style params: ?filter[title]=go&filter[price][min]=10
+//   - missing params are taken from the default tag: `query:"limit" default:"20"`
+//   - field types must implement WithParser, encoding.TextUnmarshaler or be one of the basic types
+//
+// The decoded struct is validated if it implements WithValidation.
+// QueryStruct panics if T is not a struct, a field has an unsupported type or an invalid default.
+// Example:
+//
+//	type booksFilter struct {
+//		Title  *string  `query:"title"`
+//		Tags   []string `query:"tag"`
+//		Price  struct {
+//			Min int `query:"min"`
+//			Max int `query:"max" default:"1000"`
+//		} `query:"price"`
+//		Limit int `query:"limit" default:"20"`
+//	}
+//
+//	filter := geh.QueryStruct[booksFilter]().Attach(builder)
+func QueryStruct[T any]() *QueryStructType[T] {
+	t := typeOf[T]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("QueryStruct: %s is not a struct", t))
+	}
+	if err := checkQueryValues(t, queryStructTagName); err != nil {
+		panic(fmt.Sprintf("QueryStruct: %s: %v", t, err))
+	}
+	return &QueryStructType[T]{}
+}
+
+func (q *QueryStructType[T]) Attach(b ParserAdder) *AttachedQueryStruct[T] {
+	a := &AttachedQueryStruct[T]{q}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedQueryStruct[T any] struct {
+	q *QueryStructType[T]
+}
+
+func (p *AttachedQueryStruct[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	var v T
+	if err := decodeQueryValues(ctx, r.URL.Query(), &v, queryStructTagName); err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrQueryStructParsing)
+	}
+	if err := ValidateWithValidation(v); err != nil {
+		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrQueryStructValidation)
+	}
+	return context.WithValue(ctx, p, v), nil
+}
+
+// Describe describes every query param of the struct as optional.
+func (p *AttachedQueryStruct[T]) Describe() []ParamDescription {
+	var params []ParamDescription
+	for _, f := range describeValues(typeOf[T](), queryStructTagName, "") {
+		params = append(params, ParamDescription{
+			Location: ParamLocationQuery,
+			Name:     f.name,
+			Type:     f.t,
+			ErrorStatuses: uniqueStatuses(
+				defaultHttpStatusCodeErrQueryStructParsing,
+				defaultHttpStatusCodeErrQueryStructValidation,
+			),
+		})
+	}
+	return params
+}
+
+func (p *AttachedQueryStruct[T]) Get(r *http.Request) T {
+	return p.GetContext(r.Context())
+}
+
+func (p *AttachedQueryStruct[T]) GetContext(ctx context.Context) T {
+	return GetFromContext[T](ctx, p)
+}
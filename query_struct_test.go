@@ -0,0 +1,120 @@
+package goergohandler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+type queryPriceRange struct {
+	Min int `query:"min"`
+	Max int `query:"max" default:"1000"`
+}
+
+type queryAuthor struct {
+	Name *string `query:"name"`
+}
+
+type queryPagination struct {
+	Limit  int `query:"limit" default:"20"`
+	Offset int `query:"offset"`
+}
+
+type booksFilter struct {
+	queryPagination
+	Title  *string                   `query:"title"`
+	Tags   []string                  `query:"tag"`
+	Sort   []string                  `query:"sort" default:"title,id"`
+	Since  time.Time                 `query:"since"`
+	Cursor paramBookIDWithParserType `query:"cursor"`
+	Price  queryPriceRange           `query:"price"`
+	Author *queryAuthor              `query:"author"`
+	Skip   string                    `query:"-"`
+}
+
+func (f booksFilter) Validate() error {
+	if f.Price.Min > f.Price.Max {
+		return errors.New("price min is greater than max")
+	}
+	return nil
+}
+
+func TestQueryStruct(t *testing.T) {
+	builder := geh.New()
+	filter := geh.QueryStruct[booksFilter]().Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		f := filter.Get(r)
+		title, author := "<nil>", "<nil>"
+		if f.Title != nil {
+			title = *f.Title
+		}
+		if f.Author != nil && f.Author.Name != nil {
+			author = *f.Author.Name
+		}
+		fmt.Fprintf(w, "%d %d %s %v %v %s %s %d-%d %s",
+			f.Limit, f.Offset, title, f.Tags, f.Sort, f.Since.Format(time.DateOnly), f.Cursor, f.Price.Min, f.Price.Max, author)
+	})
+
+	testCases := []struct {
+		query  string
+		status int
+		resp   string
+	}{
+		{"", http.StatusOK, "20 0 <nil> [] [title id] 0001-01-01  0-1000 <nil>"},
+		{
+			"limit=5&offset=10&title=go&tag=a&tag[]=b&sort=-id&since=2024-05-01T00:00:00Z&cursor=abc&price[min]=10&price[max]=20&author[name]=Rob&Skip=x",
+			http.StatusOK,
+			"5 10 go [a b] [-id] 2024-05-01 abc_parsed 10-20 Rob",
+		},
+		{"limit=many", http.StatusBadRequest, `{"error":"limit: invalid int value: many"}`},
+		{"price[min]=x", http.StatusBadRequest, `{"error":"price[min]: invalid int value: x"}`},
+		{"price[min]=2000", http.StatusBadRequest, `{"error":"price min is greater than max"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?"+tc.query, nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+
+	var names []string
+	for _, p := range builder.Describe() {
+		names = append(names, p.Name)
+	}
+	require.Equal(t, []string{"limit", "offset", "title", "tag", "sort", "since", "cursor", "price[min]", "price[max]", "author[name]"}, names)
+}
+
+func TestQueryStruct_PanicsOnInvalidStruct(t *testing.T) {
+	type badDefault struct {
+		Price struct {
+			Max int `query:"max" default:"many"`
+		} `query:"price"`
+	}
+	require.PanicsWithValue(t,
+		`QueryStruct: goergohandler_test.badDefault: price[max]: invalid default "many": invalid int value: many`,
+		func() { geh.QueryStruct[badDefault]() },
+	)
+
+	type badSliceDefault struct {
+		IDs []int `query:"id" default:"1,x"`
+	}
+	require.Panics(t, func() { geh.QueryStruct[badSliceDefault]() })
+
+	type unsupportedField struct {
+		Values map[string]string `query:"values"`
+	}
+	require.PanicsWithValue(t,
+		`QueryStruct: goergohandler_test.unsupportedField: values: unsupported type map[string]string`,
+		func() { geh.QueryStruct[unsupportedField]() },
+	)
+}
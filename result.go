@@ -33,3 +33,29 @@ func TryResponseWithHttpStatus(response any) (ResponseWithHttpStatus, bool) {
 	r, ok := response.(ResponseWithHttpStatus)
 	return r, ok
 }
+
+// ResponseWithCookies is a result setting the cookies before the response is written.
+// Use EncodeCookie to set the cookies read by the parsers with a codec.
+type ResponseWithCookies struct {
+	Cookies  []*http.Cookie
+	Response any
+}
+
+func NewResponseWithCookies(response any, cookies ...*http.Cookie) ResponseWithCookies {
+	return ResponseWithCookies{
+		Cookies:  cookies,
+		Response: response,
+	}
+}
+
+// ResponseWithEnvelopeFields is a result adding fields next to the result in the response envelope:
+// {"result": EnvelopeResult(), ...EnvelopeFields()}. It is supported by JSONEncoder and XMLEncoder.
+type ResponseWithEnvelopeFields interface {
+	EnvelopeResult() any
+	EnvelopeFields() map[string]any
+}
+
+// ResponseWithLinks is a result setting the Link header built from the request before the response is written.
+type ResponseWithLinks interface {
+	WriteLinks(w http.ResponseWriter, r *http.Request)
+}
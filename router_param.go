@@ -5,17 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-
-	"github.com/nktknshn/go-ergo-handler/adapters/gorilla"
 )
 
-func SetVarsGetter(varsGetter VarsGetter) {
-	defaultVarsGetter = varsGetter
-}
-
-// TODO: make it configurable
-var defaultVarsGetter VarsGetter = &gorilla.MuxVarsGetter{}
-
 const (
 	defaultHttpStatusCodeErrRouterParamParsing    = http.StatusBadRequest
 	defaultHttpStatusCodeErrRouterParamMissing    = http.StatusBadRequest
@@ -30,11 +21,28 @@ func newRouterParamMissingError(paramName string) error {
 	return fmt.Errorf("%w: %s", ErrRouterParamMissing, paramName)
 }
 
+// VarsGetter gets the router params from the request. Set it with Builder.WithVarsGetter or
+// per router param with WithVarsGetter. By default gorilla/mux is used. Build with the geh_nogorilla
+// tag to use http.ServeMux by default and not to import gorilla/mux.
 type VarsGetter interface {
 	GetVar(r *http.Request, key string) (string, bool)
 }
 
-type routerParamKeyType string
+// varsGetterProvider is implemented by the ParserAdders providing the VarsGetter of the builder.
+type varsGetterProvider interface {
+	VarsGetter() VarsGetter
+}
+
+// resolveVarsGetter returns the VarsGetter of the parser, the VarsGetter of the builder or the default one.
+func resolveVarsGetter(varsGetter VarsGetter, adder ParserAdder) VarsGetter {
+	if varsGetter != nil {
+		return varsGetter
+	}
+	if p, ok := adder.(varsGetterProvider); ok && p.VarsGetter() != nil {
+		return p.VarsGetter()
+	}
+	return defaultVarsGetter
+}
 
 type RouteParamParserFunc[T any] func(ctx context.Context, v string) (T, error)
 
@@ -61,23 +69,26 @@ func (rp *RouterParamType[T]) WithMissingError(err error) *RouterParamType[T] {
 	return rp
 }
 
+// WithVarsGetter sets the VarsGetter overriding the one of the builder.
+func (rp *RouterParamType[T]) WithVarsGetter(varsGetter VarsGetter) *RouterParamType[T] {
+	rp.VarsGetter = varsGetter
+	return rp
+}
+
+// Attach attaches the parser to the builder. The VarsGetter is resolved at this moment.
 func (rp *RouterParamType[T]) Attach(builder ParserAdder) *AttachedRouterParam[T] {
-	a := &AttachedRouterParam[T]{rp}
+	a := &AttachedRouterParam[T]{rp, resolveVarsGetter(rp.VarsGetter, builder)}
 	builder.AddParser(a)
 	return a
 }
 
 type AttachedRouterParam[T any] struct {
-	rp *RouterParamType[T]
+	rp         *RouterParamType[T]
+	varsGetter VarsGetter
 }
 
 func (p *AttachedRouterParam[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
-
-	if p.rp.VarsGetter == nil {
-		p.rp.VarsGetter = defaultVarsGetter
-	}
-
-	v, ok := p.rp.VarsGetter.GetVar(r, p.rp.Name)
+	v, ok := p.varsGetter.GetVar(r, p.rp.Name)
 	if !ok {
 		err := p.rp.ErrMissing
 		if err == nil {
@@ -93,7 +104,21 @@ func (p *AttachedRouterParam[T]) ParseRequest(ctx context.Context, w http.Respon
 	if err != nil {
 		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrRouterParamValidation)
 	}
-	return context.WithValue(ctx, routerParamKeyType(p.rp.Name), vt), nil
+	return context.WithValue(ctx, p, vt), nil
+}
+
+func (p *AttachedRouterParam[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location: ParamLocationPath,
+		Name:     p.rp.Name,
+		Type:     typeOf[T](),
+		Required: true,
+		ErrorStatuses: uniqueStatuses(
+			defaultHttpStatusCodeErrRouterParamMissing,
+			defaultHttpStatusCodeErrRouterParamParsing,
+			defaultHttpStatusCodeErrRouterParamValidation,
+		),
+	}}
 }
 
 func (p *AttachedRouterParam[T]) Get(r *http.Request) T {
@@ -101,5 +126,5 @@ func (p *AttachedRouterParam[T]) Get(r *http.Request) T {
 }
 
 func (p *AttachedRouterParam[T]) GetContext(ctx context.Context) T {
-	return GetFromContext[T](ctx, routerParamKeyType(p.rp.Name))
+	return GetFromContext[T](ctx, p)
 }
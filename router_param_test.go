@@ -5,15 +5,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/gorilla/mux"
 	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/nktknshn/go-ergo-handler/adapters/gorilla"
+	"github.com/nktknshn/go-ergo-handler/adapters/stdlib"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestRouterParam(t *testing.T) {
-	builder := geh.New()
+	builder := geh.New().WithVarsGetter(gorilla.New())
 	routerParam := geh.RouterParam("id", func(ctx context.Context, v string) (int, error) {
 		return strconv.Atoi(v)
 	})
@@ -33,3 +37,63 @@ func TestRouterParam(t *testing.T) {
 
 	require.Equal(t, w.Body.String(), "1")
 }
+
+func TestRouterParam_BuilderVarsGetter(t *testing.T) {
+	// two routers in one process
+	gorillaBuilder := geh.New().WithVarsGetter(gorilla.New())
+	gorillaID := geh.RouterParamWithParser[paramBookIDWithParserType]("id").Attach(gorillaBuilder)
+	gorillaRouter := mux.NewRouter()
+	gorillaRouter.Handle("/books/{id}", gorillaBuilder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(gorillaID.Get(r)))
+	}))
+
+	stdlibBuilder := geh.New().WithVarsGetter(stdlib.New())
+	stdlibID := geh.RouterParamWithParser[paramBookIDWithParserType]("id").Attach(stdlibBuilder)
+	stdlibRouter := http.NewServeMux()
+	stdlibRouter.Handle("/books/{id}", stdlibBuilder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(stdlibID.Get(r)))
+	}))
+
+	w := httptest.NewRecorder()
+	gorillaRouter.ServeHTTP(w, httptest.NewRequest("GET", "/books/1", nil))
+	require.Equal(t, "1_parsed", w.Body.String())
+
+	w = httptest.NewRecorder()
+	stdlibRouter.ServeHTTP(w, httptest.NewRequest("GET", "/books/2", nil))
+	require.Equal(t, "2_parsed", w.Body.String())
+}
+
+func TestRouterParam_ConcurrentRequests(t *testing.T) {
+	routerParam := geh.RouterParam("id", func(ctx context.Context, v string) (int, error) {
+		return strconv.Atoi(v)
+	})
+
+	// the same parser definition is shared by builders with different routers
+	stdlibBuilder := geh.New().WithVarsGetter(stdlib.New())
+	stdlibID := routerParam.Attach(stdlibBuilder)
+	stdlibRouter := http.NewServeMux()
+	stdlibRouter.Handle("/books/{id}", stdlibBuilder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strconv.Itoa(stdlibID.Get(r))))
+	}))
+
+	gorillaBuilder := geh.New().WithVarsGetter(gorilla.New())
+	gorillaID := routerParam.Attach(gorillaBuilder)
+	gorillaRouter := mux.NewRouter()
+	gorillaRouter.Handle("/books/{id}", gorillaBuilder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strconv.Itoa(gorillaID.Get(r))))
+	}))
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		for _, router := range []http.Handler{stdlibRouter, gorillaRouter} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest("GET", "/books/"+strconv.Itoa(i), nil))
+				assert.Equal(t, strconv.Itoa(i), w.Body.String())
+			}()
+		}
+	}
+	wg.Wait()
+}
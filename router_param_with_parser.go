@@ -15,8 +15,7 @@ type RouterParamWithParserType[T WithParser[T]] struct {
 // RouterParamWithParser is same as RouterParam but it uses a parser function of the given type.
 func RouterParamWithParser[T WithParser[T]](name string) *RouterParamWithParserType[T] {
 	return &RouterParamWithParserType[T]{
-		Name:       name,
-		VarsGetter: defaultVarsGetter,
+		Name: name,
 	}
 }
 
@@ -25,21 +24,26 @@ func (rp *RouterParamWithParserType[T]) WithErrMissing(errMissing error) *Router
 	return rp
 }
 
+// WithVarsGetter sets the VarsGetter overriding the one of the builder.
+func (rp *RouterParamWithParserType[T]) WithVarsGetter(varsGetter VarsGetter) *RouterParamWithParserType[T] {
+	rp.VarsGetter = varsGetter
+	return rp
+}
+
+// Attach attaches the parser to the builder. The VarsGetter is resolved at this moment.
 func (rp *RouterParamWithParserType[T]) Attach(builder ParserAdder) *AttachedRouterParamWithParser[T] {
-	a := &AttachedRouterParamWithParser[T]{rp}
+	a := &AttachedRouterParamWithParser[T]{rp, resolveVarsGetter(rp.VarsGetter, builder)}
 	builder.AddParser(a)
 	return a
 }
 
 type AttachedRouterParamWithParser[T WithParser[T]] struct {
-	rp *RouterParamWithParserType[T]
+	rp         *RouterParamWithParserType[T]
+	varsGetter VarsGetter
 }
 
 func (p *AttachedRouterParamWithParser[T]) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
-	if p.rp.VarsGetter == nil {
-		p.rp.VarsGetter = defaultVarsGetter
-	}
-	v, ok := p.rp.VarsGetter.GetVar(r, p.rp.Name)
+	v, ok := p.varsGetter.GetVar(r, p.rp.Name)
 	if !ok {
 		err := p.rp.ErrMissing
 		if err == nil {
@@ -56,7 +60,21 @@ func (p *AttachedRouterParamWithParser[T]) ParseRequest(ctx context.Context, w h
 	if err != nil {
 		return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrRouterParamValidation)
 	}
-	return context.WithValue(ctx, routerParamKeyType(p.rp.Name), vt), nil
+	return context.WithValue(ctx, p, vt), nil
+}
+
+func (p *AttachedRouterParamWithParser[T]) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location: ParamLocationPath,
+		Name:     p.rp.Name,
+		Type:     typeOf[T](),
+		Required: true,
+		ErrorStatuses: uniqueStatuses(
+			defaultHttpStatusCodeErrRouterParamMissing,
+			defaultHttpStatusCodeErrRouterParamParsing,
+			defaultHttpStatusCodeErrRouterParamValidation,
+		),
+	}}
 }
 
 func (p *AttachedRouterParamWithParser[T]) Get(r *http.Request) T {
@@ -64,5 +82,5 @@ func (p *AttachedRouterParamWithParser[T]) Get(r *http.Request) T {
 }
 
 func (p *AttachedRouterParamWithParser[T]) GetContext(ctx context.Context) T {
-	return GetFromContext[T](ctx, routerParamKeyType(p.rp.Name))
+	return GetFromContext[T](ctx, p)
 }
@@ -0,0 +1,124 @@
+package goergohandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultHttpStatusCodeErrSortParam = http.StatusBadRequest
+)
+
+var (
+	ErrSortUnknownField   = errors.New("unknown sort field")
+	ErrSortDuplicateField = errors.New("duplicate sort field")
+	ErrSortInvalid        = errors.New("invalid sort param")
+)
+
+// SortField is a field to sort by.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+func (s SortField) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+type SortParamType struct {
+	name   string
+	fields []string
+	def    []SortField
+}
+
+// SortParam is a parser for the comma separated list of fields: ?sort=-created_at,title.
+// The field prefixed with "-" is sorted in the descending order, "+" is allowed for the ascending one.
+// Only the fields from the whitelist are accepted, others are rejected with ErrSortUnknownField and 400 status code.
+// Missing param means the default set by WithDefault, nil if not set.
+func SortParam(name string, fields ...string) *SortParamType {
+	return &SortParamType{name: name, fields: fields}
+}
+
+// WithDefault sets the sort used when the param is missing. Panics if the fields are not in the whitelist.
+func (s *SortParamType) WithDefault(fields ...string) *SortParamType {
+	def, err := s.parse(strings.Join(fields, ","))
+	if err != nil {
+		panic(fmt.Sprintf("SortParam: %v", err))
+	}
+	s.def = def
+	return s
+}
+
+func (s *SortParamType) parse(value string) ([]SortField, error) {
+	var sort []SortField
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		f := SortField{Field: item}
+		switch item[0] {
+		case '-':
+			f = SortField{Field: item[1:], Desc: true}
+		case '+':
+			f.Field = item[1:]
+		}
+		if f.Field == "" {
+			return nil, fmt.Errorf("%w: %s: %q", ErrSortInvalid, s.name, item)
+		}
+		if !slices.Contains(s.fields, f.Field) {
+			return nil, fmt.Errorf("%w: %s: %s, allowed: %s", ErrSortUnknownField, s.name, f.Field, strings.Join(s.fields, ", "))
+		}
+		if slices.ContainsFunc(sort, func(sf SortField) bool { return sf.Field == f.Field }) {
+			return nil, fmt.Errorf("%w: %s: %s", ErrSortDuplicateField, s.name, f.Field)
+		}
+		sort = append(sort, f)
+	}
+	return sort, nil
+}
+
+func (s *SortParamType) Attach(b ParserAdder) *AttachedSortParam {
+	a := &AttachedSortParam{s}
+	b.AddParser(a)
+	return a
+}
+
+type AttachedSortParam struct {
+	s *SortParamType
+}
+
+func (p *AttachedSortParam) ParseRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	sort := p.s.def
+	if value := r.URL.Query().Get(p.s.name); value != "" {
+		var err error
+		sort, err = p.s.parse(value)
+		if err != nil {
+			return ctx, WrapWithStatusCode(err, defaultHttpStatusCodeErrSortParam)
+		}
+	}
+	return context.WithValue(ctx, p, sort), nil
+}
+
+func (p *AttachedSortParam) Describe() []ParamDescription {
+	return []ParamDescription{{
+		Location:      ParamLocationQuery,
+		Name:          p.s.name,
+		Type:          typeOf[string](),
+		ErrorStatuses: uniqueStatuses(defaultHttpStatusCodeErrSortParam),
+	}}
+}
+
+func (p *AttachedSortParam) Get(r *http.Request) []SortField {
+	return p.GetContext(r.Context())
+}
+
+func (p *AttachedSortParam) GetContext(ctx context.Context) []SortField {
+	return GetFromContext[[]SortField](ctx, p)
+}
@@ -0,0 +1,44 @@
+package goergohandler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortParam(t *testing.T) {
+	builder := geh.New()
+	sort := geh.SortParam("sort", "created_at", "title", "price").WithDefault("-created_at").Attach(builder)
+
+	handler := builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%v", sort.Get(r))
+	})
+
+	testCases := []struct {
+		query  string
+		status int
+		resp   string
+	}{
+		{"", http.StatusOK, "[-created_at]"},
+		{"sort=-created_at,title", http.StatusOK, "[-created_at title]"},
+		{"sort=%2Bprice,,title", http.StatusOK, "[price title]"},
+		{"sort=author", http.StatusBadRequest, `{"error":"unknown sort field: sort: author, allowed: created_at, title, price"}`},
+		{"sort=title,-title", http.StatusBadRequest, `{"error":"duplicate sort field: sort: title"}`},
+		{"sort=-", http.StatusBadRequest, `{"error":"invalid sort param: sort: \"-\""}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?"+tc.query, nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.resp, w.Body.String())
+		})
+	}
+
+	require.Panics(t, func() { geh.SortParam("sort", "title").WithDefault("author") })
+}
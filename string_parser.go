@@ -0,0 +1,102 @@
+package goergohandler
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	contextType         = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+	stringType          = reflect.TypeOf("")
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// stringParserFunc parses a string into a value of the type the function was made for.
+type stringParserFunc = func(ctx context.Context, v string) (any, error)
+
+// stringParserFor returns a function that parses a string into a value of type t.
+// Types implementing WithParser[t] or encoding.TextUnmarshaler, time.Duration and basic kinds are supported.
+func stringParserFor(t reflect.Type) (stringParserFunc, bool) {
+	if m, ok := t.MethodByName("Parse"); ok && isWithParserMethod(t, m.Type) {
+		return func(ctx context.Context, v string) (any, error) {
+			out := m.Func.Call([]reflect.Value{reflect.Zero(t), reflect.ValueOf(&ctx).Elem(), reflect.ValueOf(v)})
+			if err, _ := out[1].Interface().(error); err != nil {
+				return nil, err
+			}
+			return out[0].Interface(), nil
+		}, true
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return func(ctx context.Context, v string) (any, error) {
+			ptr := reflect.New(t)
+			if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(v)); err != nil {
+				return nil, err
+			}
+			return ptr.Elem().Interface(), nil
+		}, true
+	}
+
+	if t == durationType {
+		return func(ctx context.Context, v string) (any, error) {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid duration value: %s", v)
+			}
+			return d, nil
+		}, true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(ctx context.Context, v string) (any, error) {
+			return reflect.ValueOf(v).Convert(t).Interface(), nil
+		}, true
+	case reflect.Bool:
+		return func(ctx context.Context, v string) (any, error) {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bool value: %s", v)
+			}
+			return reflect.ValueOf(b).Convert(t).Interface(), nil
+		}, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(ctx context.Context, v string) (any, error) {
+			i, err := strconv.ParseInt(v, 10, t.Bits())
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value: %s", t.Kind(), v)
+			}
+			return reflect.ValueOf(i).Convert(t).Interface(), nil
+		}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(ctx context.Context, v string) (any, error) {
+			i, err := strconv.ParseUint(v, 10, t.Bits())
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value: %s", t.Kind(), v)
+			}
+			return reflect.ValueOf(i).Convert(t).Interface(), nil
+		}, true
+	case reflect.Float32, reflect.Float64:
+		return func(ctx context.Context, v string) (any, error) {
+			f, err := strconv.ParseFloat(v, t.Bits())
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value: %s", t.Kind(), v)
+			}
+			return reflect.ValueOf(f).Convert(t).Interface(), nil
+		}, true
+	}
+
+	return nil, false
+}
+
+// isWithParserMethod checks the method type is func(T, context.Context, string) (T, error).
+func isWithParserMethod(t reflect.Type, m reflect.Type) bool {
+	return m.NumIn() == 3 && m.In(1) == contextType && m.In(2) == stringType &&
+		m.NumOut() == 2 && m.Out(0) == t && m.Out(1) == errorType
+}
@@ -0,0 +1,59 @@
+package goergohandler
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+var (
+	ErrInvalidUUID = errors.New("invalid uuid")
+)
+
+// UUID is a RFC 9562 UUID. It is parsed from and formatted to the canonical
+// xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx form. The version is not checked.
+type UUID [16]byte
+
+// ParseUUID parses the canonical form of UUID, case insensitive.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, ErrInvalidUUID
+	}
+	hexStr := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(u[:], []byte(hexStr)); err != nil {
+		return UUID{}, ErrInvalidUUID
+	}
+	return u, nil
+}
+
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// IsZero reports whether the UUID is the nil UUID.
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
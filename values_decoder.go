@@ -0,0 +1,262 @@
+package goergohandler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const defaultTagName = "default"
+
+// decodeValues sets the fields of the struct pointed by v from the values.
+// The value name is taken from the tag, the field name is used if the tag is missing.
+// Fields tagged with "-" are skipped. Pointer fields are set only if the value is present.
+// Slice fields get all the values of the name. It is used by the form decoders.
+func decodeValues(ctx context.Context, values url.Values, v any, tagName string) error {
+	return decodeValuesStruct(ctx, values, v, tagName, false)
+}
+
+// decodeQueryValues is decodeValues extended for the query strings:
+// slice fields also get the values of name[],
+// nested struct fields are decoded from the deep object style names: filter[title], filter[author][name],
+// missing values are taken from the default tag if present: `default:"20"`, slices split it by comma.
+func decodeQueryValues(ctx context.Context, values url.Values, v any, tagName string) error {
+	return decodeValuesStruct(ctx, values, v, tagName, true)
+}
+
+func decodeValuesStruct(ctx context.Context, values url.Values, v any, tagName string, extended bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode values into %T", v)
+	}
+	return decodeStructValues(ctx, values, rv.Elem(), tagName, "", extended)
+}
+
+func decodeStructValues(ctx context.Context, values url.Values, rv reflect.Value, tagName, prefix string, extended bool) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, skip := valueFieldKey(f, tagName, prefix)
+		if skip {
+			continue
+		}
+		field := rv.Field(i)
+
+		if extended && isNestedValuesStruct(f.Type) {
+			if f.Anonymous && f.Tag.Get(tagName) == "" {
+				key = prefix
+			}
+			if f.Type.Kind() == reflect.Pointer {
+				if !f.IsExported() || !hasValuesWithPrefix(values, key+"[") {
+					continue
+				}
+				field.Set(reflect.New(f.Type.Elem()))
+				field = field.Elem()
+			}
+			if err := decodeStructValues(ctx, values, field, tagName, key, extended); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		vals := values[key]
+		if extended {
+			vals = slices.Concat(vals, values[key+"[]"])
+		}
+		if len(vals) == 0 {
+			def, ok := f.Tag.Lookup(defaultTagName)
+			if !extended || !ok {
+				continue
+			}
+			vals = defaultValues(f.Type, def)
+		}
+		if err := setFieldFromStrings(ctx, field, vals); err != nil {
+			return &valuesFieldError{key: key, t: f.Type, vals: vals, err: err}
+		}
+	}
+	return nil
+}
+
+// defaultValues splits the default of the slice fields by comma.
+func defaultValues(t reflect.Type, def string) []string {
+	if t.Kind() == reflect.Slice {
+		return strings.Split(def, ",")
+	}
+	return []string{def}
+}
+
+// valuesFieldError is the error of decoding the values of the key into the field of type t.
+type valuesFieldError struct {
+	key  string
+	t    reflect.Type
+	vals []string
+	err  error
+}
+
+func (e *valuesFieldError) Error() string {
+	return e.key + ": " + e.err.Error()
+}
+
+func (e *valuesFieldError) Unwrap() error {
+	return e.err
+}
+
+// valueFieldKey returns the name of the value for the field. Fields tagged with "-" and unexported
+// fields are skipped unless they are embedded structs.
+func valueFieldKey(f reflect.StructField, tagName, prefix string) (string, bool) {
+	name, _, _ := strings.Cut(f.Tag.Get(tagName), ",")
+	if name == "-" || (!f.IsExported() && !f.Anonymous) {
+		return "", true
+	}
+	if name == "" {
+		name = f.Name
+	}
+	if prefix != "" {
+		name = prefix + "[" + name + "]"
+	}
+	return name, false
+}
+
+// isNestedValuesStruct reports whether the field of type t is decoded as a nested struct.
+// Structs parsed from a string, like time.Time, are not.
+func isNestedValuesStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := stringParserFor(t)
+	return !ok
+}
+
+func hasValuesWithPrefix(values url.Values, prefix string) bool {
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesField is a value name and the type of the field decodeQueryValues sets from it.
+type valuesField struct {
+	name string
+	t    reflect.Type
+	// def is the default tag of the field, if any
+	def *string
+}
+
+// describeValues lists the value names decodeQueryValues reads into the struct of type t.
+func describeValues(t reflect.Type, tagName, prefix string) []valuesField {
+	var fields []valuesField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, skip := valueFieldKey(f, tagName, prefix)
+		if skip {
+			continue
+		}
+		if isNestedValuesStruct(f.Type) {
+			if f.Anonymous && f.Tag.Get(tagName) == "" {
+				key = prefix
+			}
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			fields = append(fields, describeValues(ft, tagName, key)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		field := valuesField{name: key, t: f.Type}
+		if def, ok := f.Tag.Lookup(defaultTagName); ok {
+			field.def = &def
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// checkQueryValues checks that decodeQueryValues can decode into the struct of type t:
+// the types of the fields are supported and the defaults are parsed.
+func checkQueryValues(t reflect.Type, tagName string) error {
+	for _, f := range describeValues(t, tagName, "") {
+		if err := checkValuesType(f.t); err != nil {
+			return &valuesFieldError{key: f.name, t: f.t, err: err}
+		}
+		if f.def == nil {
+			continue
+		}
+		vals := defaultValues(f.t, *f.def)
+		if err := setFieldFromStrings(context.Background(), reflect.New(f.t).Elem(), vals); err != nil {
+			return &valuesFieldError{key: f.name, t: f.t, vals: vals, err: fmt.Errorf("invalid default %q: %w", *f.def, err)}
+		}
+	}
+	return nil
+}
+
+// checkValuesType returns the error of setFieldFromStrings for the unsupported types.
+func checkValuesType(t reflect.Type) error {
+	if t.Kind() == reflect.Pointer {
+		return checkValuesType(t.Elem())
+	}
+	if _, ok := stringParserFor(t); ok {
+		return nil
+	}
+	if t.Kind() == reflect.Slice {
+		if _, ok := stringParserFor(t.Elem()); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported type %s", t)
+}
+
+// setFieldFromStrings parses the strings into the field. Slices get all the strings,
+// other types get the first one.
+func setFieldFromStrings(ctx context.Context, field reflect.Value, vals []string) error {
+	t := field.Type()
+	if t.Kind() == reflect.Pointer {
+		ptr := reflect.New(t.Elem())
+		if err := setFieldFromStrings(ctx, ptr.Elem(), vals); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if parse, ok := stringParserFor(t); ok {
+		v, err := parse(ctx, vals[0])
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	if t.Kind() == reflect.Slice {
+		parse, ok := stringParserFor(t.Elem())
+		if !ok {
+			return fmt.Errorf("unsupported type %s", t)
+		}
+		slice := reflect.MakeSlice(t, len(vals), len(vals))
+		for i, s := range vals {
+			v, err := parse(ctx, s)
+			if err != nil {
+				return fmt.Errorf("at index %d: %w", i, err)
+			}
+			slice.Index(i).Set(reflect.ValueOf(v))
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported type %s", t)
+}
@@ -0,0 +1,8 @@
+//go:build !geh_nogorilla
+
+package goergohandler
+
+import "github.com/nktknshn/go-ergo-handler/adapters/gorilla"
+
+// defaultVarsGetter is gorilla/mux unless the package is built with the geh_nogorilla tag.
+var defaultVarsGetter VarsGetter = &gorilla.MuxVarsGetter{}
@@ -0,0 +1,8 @@
+//go:build geh_nogorilla
+
+package goergohandler
+
+import "github.com/nktknshn/go-ergo-handler/adapters/stdlib"
+
+// defaultVarsGetter is http.ServeMux when the package is built with the geh_nogorilla tag.
+var defaultVarsGetter VarsGetter = &stdlib.ServeMuxVarsGetter{}
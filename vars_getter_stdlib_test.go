@@ -0,0 +1,27 @@
+//go:build geh_nogorilla
+
+package goergohandler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	geh "github.com/nktknshn/go-ergo-handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultVarsGetter_ServeMux(t *testing.T) {
+	builder := geh.New()
+	id := geh.RouterParamString("id").Attach(builder)
+
+	mux := http.NewServeMux()
+	mux.Handle("/books/{id}", builder.BuildHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(id.Get(r)))
+	}))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/books/1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Body.String())
+}